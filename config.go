@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Vabavia/rd450x-fan-control/logger"
+)
+
+// CurvePoint is one breakpoint of a fan curve: at TempC degrees Celsius,
+// the fan should run at PWMPercent.
+type CurvePoint struct {
+	TempC      float64 `yaml:"temp_c"`
+	PWMPercent int     `yaml:"pwm_percent"`
+}
+
+// FanCurve describes how a single fan's PWM should track a temperature
+// source, modeled on fan2go's curve config.
+type FanCurve struct {
+	Fan        string       `yaml:"fan"`
+	Sensor     string       `yaml:"sensor"` // "cpu", "inlet", or "max"
+	Points     []CurvePoint `yaml:"points"`
+	MinPWM     int          `yaml:"min_pwm"`
+	MaxPWM     int          `yaml:"max_pwm"`
+	Hysteresis int          `yaml:"hysteresis"`
+}
+
+// DaemonConfig is the top-level config.yaml schema for `daemon` mode.
+type DaemonConfig struct {
+	PollIntervalSeconds int           `yaml:"poll_interval_seconds"`
+	StateFile           string        `yaml:"state_file"`
+	CPUSensor           string        `yaml:"cpu_sensor"`
+	InletSensor         string        `yaml:"inlet_sensor"`
+	Fans                []FanCurve    `yaml:"fans"`
+	Logging             logger.Config `yaml:"logging"`
+}
+
+// PollInterval returns the configured poll interval, defaulting to 2s.
+func (c DaemonConfig) PollInterval() time.Duration {
+	if c.PollIntervalSeconds <= 0 {
+		return 2 * time.Second
+	}
+	return time.Duration(c.PollIntervalSeconds) * time.Second
+}
+
+// LoadDaemonConfig reads and validates a daemon config file. Both YAML and
+// JSON are accepted since JSON is valid YAML.
+func LoadDaemonConfig(path string) (DaemonConfig, error) {
+	var cfg DaemonConfig
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, fmt.Errorf("reading config %s: %w", path, err)
+	}
+
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("parsing config %s: %w", path, err)
+	}
+
+	if cfg.StateFile == "" {
+		cfg.StateFile = "/var/lib/rd450x-fan-control/state.json"
+	}
+	if cfg.CPUSensor == "" {
+		cfg.CPUSensor = "CPU Temp"
+	}
+	if cfg.InletSensor == "" {
+		cfg.InletSensor = "Inlet Temp"
+	}
+
+	for i, fan := range cfg.Fans {
+		if len(fan.Points) < 2 {
+			return cfg, fmt.Errorf("fan %q: curve needs at least 2 points", fan.Fan)
+		}
+		if fan.MaxPWM == 0 {
+			cfg.Fans[i].MaxPWM = 100
+		}
+	}
+
+	return cfg, nil
+}
+
+// pwmForTemp linearly interpolates the curve's breakpoints to find the PWM
+// percentage for the given temperature, clamped to [MinPWM, MaxPWM].
+func (fc FanCurve) pwmForTemp(tempC float64) int {
+	points := fc.Points
+	pwm := points[len(points)-1].PWMPercent
+
+	switch {
+	case tempC <= points[0].TempC:
+		pwm = points[0].PWMPercent
+	case tempC >= points[len(points)-1].TempC:
+		pwm = points[len(points)-1].PWMPercent
+	default:
+		for i := 0; i < len(points)-1; i++ {
+			a, b := points[i], points[i+1]
+			if tempC >= a.TempC && tempC <= b.TempC {
+				if b.TempC == a.TempC {
+					pwm = a.PWMPercent
+					break
+				}
+				frac := (tempC - a.TempC) / (b.TempC - a.TempC)
+				pwm = a.PWMPercent + int(frac*float64(b.PWMPercent-a.PWMPercent))
+				break
+			}
+		}
+	}
+
+	if pwm < fc.MinPWM {
+		pwm = fc.MinPWM
+	}
+	if pwm > fc.MaxPWM {
+		pwm = fc.MaxPWM
+	}
+	return pwm
+}
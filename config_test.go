@@ -0,0 +1,49 @@
+package main
+
+import "testing"
+
+func TestFanCurvePWMForTemp(t *testing.T) {
+	fc := FanCurve{
+		Points: []CurvePoint{
+			{TempC: 30, PWMPercent: 20},
+			{TempC: 50, PWMPercent: 60},
+			{TempC: 70, PWMPercent: 100},
+		},
+		MinPWM: 10,
+		MaxPWM: 100,
+	}
+
+	cases := []struct {
+		temp float64
+		want int
+	}{
+		{temp: 10, want: 20},  // below first breakpoint, clamps to first PWM
+		{temp: 30, want: 20},  // exactly on a breakpoint
+		{temp: 40, want: 40},  // midway between 30/20% and 50/60%
+		{temp: 90, want: 100}, // above last breakpoint, clamps to last PWM
+	}
+
+	for _, c := range cases {
+		if got := fc.pwmForTemp(c.temp); got != c.want {
+			t.Errorf("pwmForTemp(%v) = %d, want %d", c.temp, got, c.want)
+		}
+	}
+}
+
+func TestFanCurvePWMForTempClampsToMinMax(t *testing.T) {
+	fc := FanCurve{
+		Points: []CurvePoint{
+			{TempC: 0, PWMPercent: 0},
+			{TempC: 100, PWMPercent: 100},
+		},
+		MinPWM: 25,
+		MaxPWM: 80,
+	}
+
+	if got := fc.pwmForTemp(5); got != 25 {
+		t.Errorf("pwmForTemp(5) = %d, want clamped MinPWM 25", got)
+	}
+	if got := fc.pwmForTemp(95); got != 80 {
+		t.Errorf("pwmForTemp(95) = %d, want clamped MaxPWM 80", got)
+	}
+}
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/Vabavia/rd450x-fan-control/ipmi"
+	"github.com/Vabavia/rd450x-fan-control/logger"
+	"github.com/Vabavia/rd450x-fan-control/systemd"
+)
+
+// daemonState is persisted across restarts so the fan initialization ramp
+// does not need to re-run on every start.
+type daemonState struct {
+	MinRPM map[string]int `json:"min_rpm"`
+}
+
+func loadDaemonState(path string) daemonState {
+	state := daemonState{MinRPM: make(map[string]int)}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return state
+	}
+	if err := json.Unmarshal(data, &state); err != nil || state.MinRPM == nil {
+		state.MinRPM = make(map[string]int)
+	}
+	return state
+}
+
+func saveDaemonState(path string, state daemonState) error {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// maxConsecutiveFanFailures is how many back-to-back polls a fan may report
+// 0 RPM while commanded above 0% before the safety net kicks in.
+const maxConsecutiveFanFailures = 3
+
+// currentRPM looks up a fan's current RPM from a freshly parsed sensor list.
+func currentRPM(readings []SensorReading, fanName string) (int, bool) {
+	for _, r := range readings {
+		if r.IsFan && r.Name == fanName {
+			return int(r.Value), true
+		}
+	}
+	return 0, false
+}
+
+// runFanInit ramps every configured fan from 0% to 100% in steps, recording
+// the first non-zero RPM each fan reports as its min-responsive RPM. This
+// runs once per fan; the result is cached in state.
+func runFanInit(client ipmi.Client, cfg DaemonConfig, state *daemonState, log *logger.Logger) {
+	log.Infof("[init] ramping fans 0->100%% to find min-responsive RPM")
+	for _, fc := range cfg.Fans {
+		fanID, ok := ipmi.FanIDByName[fc.Fan]
+		if !ok {
+			log.Warnf("[init] skipping unknown fan %q", fc.Fan)
+			continue
+		}
+		if _, done := state.MinRPM[fc.Fan]; done {
+			continue
+		}
+
+		found := false
+		for pwm := 0; pwm <= 100; pwm += 10 {
+			if err := client.SetPWM(fanID, pwm); err != nil {
+				log.Warnf("[init] %s: SetPWM(%d%%): %v", fc.Fan, pwm, err)
+				continue
+			}
+			time.Sleep(2 * time.Second)
+
+			raw, err := client.SensorList()
+			if err != nil {
+				continue
+			}
+			if rpm, ok := currentRPM(parseSensorList(raw), fc.Fan); ok && rpm > 0 {
+				state.MinRPM[fc.Fan] = rpm
+				log.Infof("[init] %s: responsive at %d%% (%d RPM)", fc.Fan, pwm, rpm)
+				found = true
+				break
+			}
+		}
+		if !found {
+			log.Warnf("[init] %s: never reported a non-zero RPM during ramp", fc.Fan)
+		}
+	}
+}
+
+// RunDaemon starts the closed-loop fan controller: it runs the one-time fan
+// initialization ramp if needed, then polls temperatures and drives each
+// configured fan's PWM according to its curve until interrupted.
+func RunDaemon(client ipmi.Client, configPath string) error {
+	cfg, err := LoadDaemonConfig(configPath)
+	if err != nil {
+		return err
+	}
+	log := logger.New(cfg.Logging)
+
+	state := loadDaemonState(cfg.StateFile)
+	runFanInit(client, cfg, &state, log)
+	if err := saveDaemonState(cfg.StateFile, state); err != nil {
+		log.Warnf("[daemon] could not save state file: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	currentPWM := make(map[string]int)
+	failCounts := make(map[string]int)
+	ticker := time.NewTicker(cfg.PollInterval())
+	defer ticker.Stop()
+
+	log.Infof("[daemon] running, poll interval %s", cfg.PollInterval())
+	if err := systemd.Ready(); err != nil {
+		log.Warnf("[daemon] sd_notify READY failed: %v", err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Infof("[daemon] shutting down, restoring BMC auto control")
+			if err := systemd.Notify("STOPPING=1"); err != nil {
+				log.Warnf("[daemon] sd_notify STOPPING failed: %v", err)
+			}
+			if err := client.RestoreAutoControl(); err != nil {
+				log.Warnf("[daemon] RestoreAutoControl: %v", err)
+			}
+			return nil
+		case <-ticker.C:
+			pollOnce(client, cfg, currentPWM, failCounts, log)
+			if err := systemd.Watchdog(); err != nil {
+				log.Warnf("[daemon] sd_notify WATCHDOG failed: %v", err)
+			}
+		}
+	}
+}
+
+// pollOnce reads sensors once and applies each fan curve, only issuing a
+// SetPWM when the change exceeds the fan's configured hysteresis. It then
+// checks for failing fans and PSUs, forcing every fan to 100% as a safety
+// net if either is detected.
+func pollOnce(client ipmi.Client, cfg DaemonConfig, currentPWM map[string]int, failCounts map[string]int, log *logger.Logger) {
+	raw, err := client.SensorList()
+	if err != nil {
+		log.Errorf("[daemon] SensorList: %v", err)
+		return
+	}
+	readings := parseSensorList(raw)
+
+	for _, fc := range cfg.Fans {
+		fanID, ok := ipmi.FanIDByName[fc.Fan]
+		if !ok {
+			continue
+		}
+
+		temp, ok := resolveTemp(readings, fc.Sensor, cfg.CPUSensor, cfg.InletSensor)
+		if !ok {
+			log.Warnf("[daemon] %s: no reading for sensor %q", fc.Fan, fc.Sensor)
+			continue
+		}
+
+		target := fc.pwmForTemp(temp)
+		if prev, ok := currentPWM[fc.Fan]; ok {
+			diff := target - prev
+			if diff < 0 {
+				diff = -diff
+			}
+			if diff <= fc.Hysteresis {
+				continue
+			}
+		}
+
+		if err := client.SetPWM(fanID, target); err != nil {
+			log.Errorf("[daemon] %s: SetPWM(%d%%): %v", fc.Fan, target, err)
+			continue
+		}
+		currentPWM[fc.Fan] = target
+		log.Debugf("[daemon] %s: temp=%.1f target=%d%%", fc.Fan, temp, target)
+	}
+
+	for _, fc := range cfg.Fans {
+		rpm, ok := currentRPM(readings, fc.Fan)
+		if ok && rpm == 0 && currentPWM[fc.Fan] > 0 {
+			failCounts[fc.Fan]++
+		} else {
+			failCounts[fc.Fan] = 0
+		}
+
+		if failCounts[fc.Fan] >= maxConsecutiveFanFailures {
+			log.Errorf("[daemon] CRITICAL: %s reported 0 RPM for %d consecutive polls while commanded %d%%, forcing all fans to 100%%",
+				fc.Fan, failCounts[fc.Fan], currentPWM[fc.Fan])
+			forceAllFansFull(client, cfg, currentPWM, log)
+			break
+		}
+	}
+
+	if psuHasFault(client, log) {
+		log.Errorf("[daemon] CRITICAL: PSU fault detected, forcing all fans to 100%%")
+		forceAllFansFull(client, cfg, currentPWM, log)
+	}
+}
+
+// forceAllFansFull overrides every configured fan's curve, driving it to
+// 100% as a safety-of-last-resort response to a failing fan or PSU.
+func forceAllFansFull(client ipmi.Client, cfg DaemonConfig, currentPWM map[string]int, log *logger.Logger) {
+	for _, fc := range cfg.Fans {
+		fanID, ok := ipmi.FanIDByName[fc.Fan]
+		if !ok {
+			continue
+		}
+		if err := client.SetPWM(fanID, 100); err != nil {
+			log.Errorf("[daemon] %s: forced SetPWM(100%%): %v", fc.Fan, err)
+			continue
+		}
+		currentPWM[fc.Fan] = 100
+	}
+}
+
+// psuHasFault reports whether any present PSU is reporting an input or
+// output fault, per `ipmitool sdr type "Power Supply"`.
+func psuHasFault(client ipmi.Client, log *logger.Logger) bool {
+	raw, err := client.PowerSupplySDR()
+	if err != nil {
+		log.Warnf("[daemon] PowerSupplySDR: %v", err)
+		return false
+	}
+
+	for _, psu := range parsePSUStatuses(raw) {
+		if psu.Present && (!psu.InputOK || !psu.OutputOK) {
+			log.Warnf("[daemon] PSU %s reporting a fault (input_ok=%v output_ok=%v)", psu.Name, psu.InputOK, psu.OutputOK)
+			return true
+		}
+	}
+	return false
+}
+
+// resolveTemp maps a curve's named temperature source ("cpu", "inlet", or
+// "max") to an actual sensor reading.
+func resolveTemp(readings []SensorReading, source, cpuSensor, inletSensor string) (float64, bool) {
+	switch source {
+	case "cpu":
+		return findTemp(readings, cpuSensor)
+	case "inlet":
+		return findTemp(readings, inletSensor)
+	case "max":
+		max, found := 0.0, false
+		for _, r := range readings {
+			if r.IsFan || r.Unit != "degrees C" {
+				continue
+			}
+			if !found || r.Value > max {
+				max, found = r.Value, true
+			}
+		}
+		return max, found
+	default:
+		return findTemp(readings, source)
+	}
+}
+
+func findTemp(readings []SensorReading, name string) (float64, bool) {
+	for _, r := range readings {
+		if !r.IsFan && r.Name == name {
+			return r.Value, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Vabavia/rd450x-fan-control/ipmi"
+	"github.com/Vabavia/rd450x-fan-control/logger"
+)
+
+// mockClient is a hand-rolled ipmi.Client for exercising the daemon's
+// polling loop without a real BMC.
+type mockClient struct {
+	sensors     string
+	sensorsErr  error
+	psus        string
+	psusErr     error
+	pwms        map[string]int
+	setPWMCalls []string
+}
+
+func (m *mockClient) RawOEM(args ...string) (string, error) { return "", nil }
+
+func (m *mockClient) SensorList() (string, error) { return m.sensors, m.sensorsErr }
+
+func (m *mockClient) ChassisStatus() (string, error) { return "", nil }
+
+func (m *mockClient) PowerSupplySDR() (string, error) { return m.psus, m.psusErr }
+
+func (m *mockClient) SetPWM(fanID string, percent int) error {
+	m.setPWMCalls = append(m.setPWMCalls, fmt.Sprintf("%s=%d", fanID, percent))
+	if m.pwms == nil {
+		m.pwms = make(map[string]int)
+	}
+	for name, id := range ipmi.FanIDByName {
+		if id == fanID {
+			m.pwms[name] = percent
+		}
+	}
+	return nil
+}
+
+func (m *mockClient) GetPWMs() (map[string]int, error) { return m.pwms, nil }
+
+func (m *mockClient) RestoreAutoControl() error { return nil }
+
+func testConfig() DaemonConfig {
+	return DaemonConfig{
+		CPUSensor:   "CPU Temp",
+		InletSensor: "Inlet Temp",
+		Fans: []FanCurve{
+			{
+				Fan:    "System Fan1",
+				Sensor: "cpu",
+				Points: []CurvePoint{{TempC: 30, PWMPercent: 20}, {TempC: 60, PWMPercent: 100}},
+				MaxPWM: 100,
+				// hysteresis wide enough that a 1-2C temp wobble doesn't
+				// reissue SetPWM
+				Hysteresis: 10,
+			},
+		},
+	}
+}
+
+func sensorList(cpuTemp float64, fanRPM int) string {
+	return fmt.Sprintf(
+		"System Fan1      | %.3f      | RPM        | ok    | na        | na        | na        | na        | na        | na\n"+
+			"CPU Temp         | %.3f      | degrees C  | ok    | na        | na        | na        | na        | na        | na\n",
+		float64(fanRPM), cpuTemp)
+}
+
+func TestPollOnceHysteresisSuppressesSetPWM(t *testing.T) {
+	cfg := testConfig()
+	client := &mockClient{sensors: sensorList(40, 1000)}
+	log := logger.New(logger.Config{})
+	currentPWM := make(map[string]int)
+	failCounts := make(map[string]int)
+
+	pollOnce(client, cfg, currentPWM, failCounts, log)
+	if len(client.setPWMCalls) != 1 {
+		t.Fatalf("expected 1 SetPWM call on first poll, got %d: %v", len(client.setPWMCalls), client.setPWMCalls)
+	}
+
+	// 41C changes the target PWM by far less than the configured
+	// hysteresis of 10, so the second poll should not reissue SetPWM.
+	client.sensors = sensorList(41, 1000)
+	pollOnce(client, cfg, currentPWM, failCounts, log)
+	if len(client.setPWMCalls) != 1 {
+		t.Fatalf("expected hysteresis to suppress the second SetPWM call, got %d: %v", len(client.setPWMCalls), client.setPWMCalls)
+	}
+}
+
+func TestPollOnceForcesFullSpeedAfterConsecutiveZeroRPM(t *testing.T) {
+	cfg := testConfig()
+	client := &mockClient{sensors: sensorList(40, 0)}
+	log := logger.New(logger.Config{})
+	currentPWM := map[string]int{"System Fan1": 50}
+	failCounts := make(map[string]int)
+
+	for i := 0; i < maxConsecutiveFanFailures; i++ {
+		pollOnce(client, cfg, currentPWM, failCounts, log)
+	}
+
+	if currentPWM["System Fan1"] != 100 {
+		t.Fatalf("expected forced 100%% PWM after %d consecutive 0 RPM polls, got %d%%", maxConsecutiveFanFailures, currentPWM["System Fan1"])
+	}
+	last := client.setPWMCalls[len(client.setPWMCalls)-1]
+	if last != ipmi.FanIDByName["System Fan1"]+"=100" {
+		t.Fatalf("expected final SetPWM call to force 100%%, got %q", last)
+	}
+}
+
+func TestResolveTemp(t *testing.T) {
+	readings := []SensorReading{
+		{Name: "CPU Temp", Value: 55, Unit: "degrees C"},
+		{Name: "Inlet Temp", Value: 22, Unit: "degrees C"},
+		{Name: "System Fan1", Value: 1200, IsFan: true},
+	}
+
+	tests := []struct {
+		source string
+		want   float64
+	}{
+		{"cpu", 55},
+		{"inlet", 22},
+		{"max", 55},
+	}
+
+	for _, tt := range tests {
+		got, ok := resolveTemp(readings, tt.source, "CPU Temp", "Inlet Temp")
+		if !ok {
+			t.Errorf("resolveTemp(%q): no reading found", tt.source)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("resolveTemp(%q) = %v, want %v", tt.source, got, tt.want)
+		}
+	}
+
+	if _, ok := resolveTemp(readings, "missing-sensor", "CPU Temp", "Inlet Temp"); ok {
+		t.Errorf("resolveTemp with an unknown named sensor should fail, got a reading")
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Vabavia/rd450x-fan-control/ipmi"
+	"github.com/Vabavia/rd450x-fan-control/logger"
+	"github.com/Vabavia/rd450x-fan-control/systemd"
+)
+
+// ExporterOptions configures the `exporter` subcommand.
+type ExporterOptions struct {
+	Listen         string
+	ScrapeInterval time.Duration
+	CollectSensors bool
+	Logging        logger.Config
+}
+
+// exporterCache caches the last BMC reading so concurrent or frequent
+// scrapes don't hammer the BMC with fresh ipmitool invocations.
+type exporterCache struct {
+	mu       sync.Mutex
+	expires  time.Time
+	interval time.Duration
+	client   ipmi.Client
+	up       bool
+	pwms     map[string]int
+	readings []SensorReading
+}
+
+func (c *exporterCache) refresh() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if time.Now().Before(c.expires) {
+		return
+	}
+
+	pwms, pwmErr := c.client.GetPWMs()
+	raw, sensorErr := c.client.SensorList()
+
+	c.up = pwmErr == nil && sensorErr == nil
+	c.pwms = pwms
+	if sensorErr == nil {
+		c.readings = parseSensorList(raw)
+	} else {
+		c.readings = nil
+	}
+	c.expires = time.Now().Add(c.interval)
+}
+
+// RunExporter starts an HTTP server exposing Prometheus metrics for fan
+// RPM/PWM and thermal sensors, modeled on ipmi_exporter.
+func RunExporter(client ipmi.Client, opts ExporterOptions) error {
+	log := logger.New(opts.Logging)
+	cache := &exporterCache{client: client, interval: opts.ScrapeInterval}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		cache.refresh()
+		writeMetrics(w, cache, opts)
+	})
+
+	listener, err := net.Listen("tcp", opts.Listen)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("[exporter] listening on %s, scrape interval %s", opts.Listen, opts.ScrapeInterval)
+	if err := systemd.Ready(); err != nil {
+		log.Warnf("[exporter] sd_notify READY failed: %v", err)
+	}
+
+	return http.Serve(listener, mux)
+}
+
+func writeMetrics(w http.ResponseWriter, cache *exporterCache, opts ExporterOptions) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+	fmt.Fprintln(w, "# HELP rd450x_ipmi_up Whether the last ipmitool invocation succeeded.")
+	fmt.Fprintln(w, "# TYPE rd450x_ipmi_up gauge")
+	fmt.Fprintf(w, "rd450x_ipmi_up %d\n", boolToInt(cache.up))
+
+	fmt.Fprintln(w, "# HELP rd450x_fan_pwm_percent Fan PWM duty cycle percentage.")
+	fmt.Fprintln(w, "# TYPE rd450x_fan_pwm_percent gauge")
+	for _, name := range ipmi.FanNames {
+		if pwm, ok := cache.pwms[name]; ok {
+			fmt.Fprintf(w, "rd450x_fan_pwm_percent{fan=%q} %d\n", name, pwm)
+		}
+	}
+
+	if !opts.CollectSensors {
+		return
+	}
+
+	fmt.Fprintln(w, "# HELP rd450x_fan_rpm Fan speed in RPM.")
+	fmt.Fprintln(w, "# TYPE rd450x_fan_rpm gauge")
+	fmt.Fprintln(w, "# HELP rd450x_temperature_celsius Temperature sensor reading.")
+	fmt.Fprintln(w, "# TYPE rd450x_temperature_celsius gauge")
+	fmt.Fprintln(w, "# HELP rd450x_airflow_cfm Airflow sensor reading.")
+	fmt.Fprintln(w, "# TYPE rd450x_airflow_cfm gauge")
+
+	for _, r := range cache.readings {
+		nameUpper := strings.ToUpper(r.Name)
+		switch {
+		case r.IsFan:
+			fmt.Fprintf(w, "rd450x_fan_rpm{fan=%q} %g\n", r.Name, r.Value)
+		case strings.Contains(nameUpper, "AIRFLOW"):
+			fmt.Fprintf(w, "rd450x_airflow_cfm{sensor=%q} %g\n", r.Name, r.Value)
+		case strings.Contains(nameUpper, "TEMP"):
+			fmt.Fprintf(w, "rd450x_temperature_celsius{sensor=%q} %g\n", r.Name, r.Value)
+		}
+	}
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWriteMetrics(t *testing.T) {
+	cache := &exporterCache{
+		up:   true,
+		pwms: map[string]int{"System Fan1": 40},
+		readings: []SensorReading{
+			{Name: "System Fan1", Value: 1200, IsFan: true},
+			{Name: "CPU Temp", Value: 45, Unit: "degrees C"},
+			{Name: "System Airflow", Value: 12, Unit: "CFM"},
+		},
+	}
+
+	rec := httptest.NewRecorder()
+	writeMetrics(rec, cache, ExporterOptions{CollectSensors: true})
+	body := rec.Body.String()
+
+	for _, want := range []string{
+		`rd450x_ipmi_up 1`,
+		`rd450x_fan_pwm_percent{fan="System Fan1"} 40`,
+		`rd450x_fan_rpm{fan="System Fan1"} 1200`,
+		`rd450x_temperature_celsius{sensor="CPU Temp"} 45`,
+		`rd450x_airflow_cfm{sensor="System Airflow"} 12`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics output missing %q\n--- got ---\n%s", want, body)
+		}
+	}
+}
+
+func TestWriteMetricsSensorsDisabled(t *testing.T) {
+	cache := &exporterCache{
+		up:       true,
+		pwms:     map[string]int{"System Fan1": 40},
+		readings: []SensorReading{{Name: "System Fan1", Value: 1200, IsFan: true}},
+	}
+
+	rec := httptest.NewRecorder()
+	writeMetrics(rec, cache, ExporterOptions{CollectSensors: false})
+	body := rec.Body.String()
+
+	if strings.Contains(body, "rd450x_fan_rpm") {
+		t.Errorf("expected rd450x_fan_rpm to be omitted when CollectSensors is false, got:\n%s", body)
+	}
+	if !strings.Contains(body, `rd450x_fan_pwm_percent{fan="System Fan1"} 40`) {
+		t.Errorf("expected PWM metric to still be present, got:\n%s", body)
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+// PSUStatus is the parsed status of one power supply, from `ipmitool sdr
+// type "Power Supply"`.
+type PSUStatus struct {
+	Name     string
+	Present  bool
+	InputOK  bool
+	OutputOK bool
+	Watts    float64
+}
+
+// parsePSUStatuses parses the pipe-delimited output of `ipmitool sdr type
+// "Power Supply"` into individual PSU statuses: name | value | unit |
+// status | optional description, e.g. "PS1 Status | 450.000 | Watts | ok".
+func parsePSUStatuses(raw string) []PSUStatus {
+	var psus []PSUStatus
+
+	for _, line := range strings.Split(raw, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		parts := strings.Split(line, "|")
+		if len(parts) < 4 {
+			continue
+		}
+
+		name := strings.TrimSpace(parts[0])
+		watts, _ := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		status := strings.ToLower(strings.TrimSpace(parts[3]))
+		desc := ""
+		if len(parts) > 4 {
+			desc = strings.ToLower(strings.TrimSpace(parts[4]))
+		}
+
+		present := status != "na" && !strings.Contains(desc, "not present") && !strings.Contains(desc, "absent")
+		faulted := status == "cr" || status == "nr" || strings.Contains(desc, "fail") || strings.Contains(desc, "lost")
+
+		psus = append(psus, PSUStatus{
+			Name:     name,
+			Present:  present,
+			InputOK:  present && !faulted,
+			OutputOK: present && !faulted,
+			Watts:    watts,
+		})
+	}
+
+	return psus
+}
+
+// HealthStatus summarizes the chassis-level fault indicators parsed from
+// `ipmitool chassis status`.
+type HealthStatus struct {
+	DriveFault   bool
+	CoolingFault bool
+	Healthy      bool
+}
+
+// parseHealthStatus parses the "Key : value" lines of `ipmitool chassis
+// status` into a HealthStatus.
+func parseHealthStatus(raw string) HealthStatus {
+	h := HealthStatus{Healthy: true}
+
+	for _, line := range strings.Split(raw, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = strings.ToLower(strings.TrimSpace(value))
+		isFault := value == "true" || value == "active"
+
+		switch key {
+		case "Drive Fault":
+			h.DriveFault = isFault
+		case "Cooling/Fan Fault":
+			h.CoolingFault = isFault
+		}
+	}
+
+	h.Healthy = !h.DriveFault && !h.CoolingFault
+	return h
+}
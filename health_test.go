@@ -0,0 +1,41 @@
+package main
+
+import "testing"
+
+func TestParsePSUStatuses(t *testing.T) {
+	raw := `PS1 Status       | 450.000    | Watts      | ok
+PS2 Status       | 0.000      | Watts      | cr     | Input Lost
+`
+
+	psus := parsePSUStatuses(raw)
+	if len(psus) != 2 {
+		t.Fatalf("expected 2 PSUs, got %d: %+v", len(psus), psus)
+	}
+
+	if !psus[0].Present || !psus[0].InputOK || !psus[0].OutputOK || psus[0].Watts != 450 {
+		t.Errorf("PS1 should be healthy: %+v", psus[0])
+	}
+	if !psus[1].Present || psus[1].InputOK || psus[1].OutputOK {
+		t.Errorf("PS2 should be present but faulted: %+v", psus[1])
+	}
+}
+
+func TestParseHealthStatus(t *testing.T) {
+	raw := `System Power         : on
+Power Overload       : false
+Chassis Intrusion    : false
+Drive Fault          : false
+Cooling/Fan Fault    : true
+`
+
+	h := parseHealthStatus(raw)
+	if h.DriveFault {
+		t.Errorf("expected no drive fault")
+	}
+	if !h.CoolingFault {
+		t.Errorf("expected cooling fault")
+	}
+	if h.Healthy {
+		t.Errorf("expected overall health to be false when cooling fault is set")
+	}
+}
@@ -0,0 +1,125 @@
+// Package ipmi abstracts the transport used to talk to the BMC so that
+// callers such as the daemon and exporter can run against a mock backend
+// in tests instead of shelling out to a real ipmitool binary.
+package ipmi
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// FanNames lists the six fan headers exposed by OEM command 0x31, in the
+// order the BMC returns their PWM readings.
+var FanNames = []string{"System Fan1", "System Fan2", "System Fan3", "System Fan4", "CPU Fan1", "CPU Fan2"}
+
+// FanIDByName maps a fan's display name to its two-digit OEM fan ID.
+var FanIDByName = map[string]string{
+	"System Fan1": "01",
+	"System Fan2": "02",
+	"System Fan3": "03",
+	"System Fan4": "04",
+	"CPU Fan1":    "05",
+	"CPU Fan2":    "06",
+}
+
+// Client is the set of BMC operations the rest of this program depends on.
+// IpmitoolClient implements it by shelling out to ipmitool; NativeClient
+// implements it by speaking IPMI directly over the network.
+type Client interface {
+	// RawOEM issues a raw IPMI command under netfn 0x2e and returns the
+	// command's combined output as a string.
+	RawOEM(args ...string) (string, error)
+	// SensorList returns the raw output of `ipmitool sensor list`.
+	SensorList() (string, error)
+	// ChassisStatus returns the raw output of `ipmitool chassis status`.
+	ChassisStatus() (string, error)
+	// PowerSupplySDR returns the raw output of `ipmitool sdr type "Power Supply"`.
+	PowerSupplySDR() (string, error)
+	// SetPWM drives fan fanID (as produced by FormatFanID, or "00" for all
+	// fans) to the given percentage.
+	SetPWM(fanID string, percent int) error
+	// GetPWMs reads the current PWM percentage of every fan in FanNames.
+	GetPWMs() (map[string]int, error)
+	// RestoreAutoControl hands fan speed control back to the BMC's own
+	// firmware, undoing SetPWM. Used on daemon shutdown.
+	RestoreAutoControl() error
+}
+
+// FormatFanID normalizes a user-supplied fan identifier ("1", "01", "all",
+// "0") into the two-digit string the OEM commands expect.
+func FormatFanID(idStr string) string {
+	if strings.ToLower(idStr) == "all" || idStr == "0" || idStr == "00" {
+		return "00"
+	}
+	if len(idStr) == 1 {
+		return "0" + idStr
+	}
+	return idStr
+}
+
+// IpmitoolClient implements Client by shelling out to the ipmitool binary.
+// This is the original, default transport.
+type IpmitoolClient struct{}
+
+func (IpmitoolClient) RawOEM(args ...string) (string, error) {
+	fullArgs := append([]string{"raw", "0x2e"}, args...)
+	cmd := exec.Command("ipmitool", fullArgs...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("%v: %s", err, string(out))
+	}
+	return string(out), nil
+}
+
+func (IpmitoolClient) SensorList() (string, error) {
+	out, err := exec.Command("ipmitool", "sensor", "list").Output()
+	return string(out), err
+}
+
+func (IpmitoolClient) ChassisStatus() (string, error) {
+	out, err := exec.Command("ipmitool", "chassis", "status").Output()
+	return string(out), err
+}
+
+func (IpmitoolClient) PowerSupplySDR() (string, error) {
+	out, err := exec.Command("ipmitool", "sdr", "type", "Power Supply").Output()
+	return string(out), err
+}
+
+func (c IpmitoolClient) SetPWM(fanID string, percent int) error {
+	hexSpeed := fmt.Sprintf("0x%x", percent)
+	_, err := c.RawOEM("0x30", "00", fanID, hexSpeed)
+	return err
+}
+
+// RestoreAutoControl re-enables full automatic fan speed control. 0x01 is
+// the OEM mode byte this board's BMC treats as "automatic"; it is the
+// counterpart to the manual-mode SetPWM OEM command.
+func (c IpmitoolClient) RestoreAutoControl() error {
+	_, err := c.RawOEM("0x30", "01", "01")
+	return err
+}
+
+func (c IpmitoolClient) GetPWMs() (map[string]int, error) {
+	pwms := make(map[string]int)
+
+	out, err := c.RawOEM("0x31")
+	if err != nil {
+		return pwms, err
+	}
+
+	parts := strings.Fields(out)
+	if len(parts) < 7 {
+		return pwms, nil
+	}
+	for i, name := range FanNames {
+		dec, err := strconv.ParseInt(parts[i+1], 16, 64)
+		if err != nil {
+			continue
+		}
+		pwms[name] = int(dec)
+	}
+	return pwms, nil
+}
@@ -0,0 +1,20 @@
+package ipmi
+
+import "testing"
+
+func TestFormatFanID(t *testing.T) {
+	cases := map[string]string{
+		"all": "00",
+		"ALL": "00",
+		"0":   "00",
+		"00":  "00",
+		"1":   "01",
+		"01":  "01",
+		"06":  "06",
+	}
+	for in, want := range cases {
+		if got := FormatFanID(in); got != want {
+			t.Errorf("FormatFanID(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package ipmi
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"testing"
+)
+
+func TestAESCBCRoundTrip(t *testing.T) {
+	key := bytes.Repeat([]byte{0x42}, 16)
+	plaintext := []byte("set fan speed 0x30 0x00 0x32")
+
+	ciphertext, err := aesCBCEncrypt(key, plaintext)
+	if err != nil {
+		t.Fatalf("aesCBCEncrypt: %v", err)
+	}
+
+	got, err := aesCBCDecrypt(key, ciphertext)
+	if err != nil {
+		t.Fatalf("aesCBCDecrypt: %v", err)
+	}
+	if !bytes.Equal(got, plaintext) {
+		t.Errorf("round-tripped plaintext = %q, want %q", got, plaintext)
+	}
+}
+
+func TestIntegrityTagLength(t *testing.T) {
+	k1 := bytes.Repeat([]byte{0x01}, sha1.Size)
+
+	tag, err := integrityTag(IntegrityAlgoHMACSHA1_96, k1, []byte("some packet bytes"))
+	if err != nil {
+		t.Fatalf("integrityTag: %v", err)
+	}
+	if len(tag) != 12 {
+		t.Errorf("HMAC-SHA1-96 tag length = %d, want 12", len(tag))
+	}
+}
+
+func TestDeriveK1K2Deterministic(t *testing.T) {
+	sik := []byte("session-integrity-key-material!")
+	k1a, k2a := deriveK1K2(sha1.New, sik)
+	k1b, k2b := deriveK1K2(sha1.New, sik)
+
+	if !bytes.Equal(k1a, k1b) || !bytes.Equal(k2a, k2b) {
+		t.Error("deriveK1K2 is not deterministic for the same SIK")
+	}
+	if bytes.Equal(k1a, k2a) {
+		t.Error("K1 and K2 must differ")
+	}
+}
@@ -0,0 +1,273 @@
+package ipmi
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// GenericClient implements Client on top of any transport (RMCP+/LAN via
+// netTransport, or the local BMC device via devTransport), so the OEM fan
+// commands and SDR-based sensor reporting only need to be written once.
+type GenericClient struct {
+	t transport
+}
+
+// Close releases the underlying transport (closing the IPMI session for
+// netTransport, or the device file for devTransport).
+func (g *GenericClient) Close() error {
+	return g.t.close()
+}
+
+func (g *GenericClient) RawOEM(args ...string) (string, error) {
+	if len(args) == 0 {
+		return "", fmt.Errorf("ipmi: RawOEM requires at least a command byte")
+	}
+	cmd, err := strconv.ParseUint(strings.TrimPrefix(args[0], "0x"), 16, 8)
+	if err != nil {
+		return "", fmt.Errorf("ipmi: invalid command byte %q: %w", args[0], err)
+	}
+	data := make([]byte, 0, len(args)-1)
+	for _, a := range args[1:] {
+		b, err := strconv.ParseUint(strings.TrimPrefix(a, "0x"), 16, 8)
+		if err != nil {
+			return "", fmt.Errorf("ipmi: invalid data byte %q: %w", a, err)
+		}
+		data = append(data, byte(b))
+	}
+
+	resp, ccode, err := g.t.sendIPMI(0x2e, byte(cmd), data)
+	if err != nil {
+		return "", err
+	}
+	if ccode != 0 {
+		return "", fmt.Errorf("ipmi: completion code 0x%02x", ccode)
+	}
+
+	parts := make([]string, len(resp))
+	for i, b := range resp {
+		parts[i] = fmt.Sprintf("%02x", b)
+	}
+	return strings.Join(parts, " "), nil
+}
+
+func (g *GenericClient) SetPWM(fanID string, percent int) error {
+	id, err := strconv.ParseUint(fanID, 16, 8)
+	if err != nil {
+		return fmt.Errorf("ipmi: invalid fan ID %q: %w", fanID, err)
+	}
+	_, ccode, err := g.t.sendIPMI(0x2e, 0x30, []byte{0x00, byte(id), byte(percent)})
+	if err != nil {
+		return err
+	}
+	if ccode != 0 {
+		return fmt.Errorf("ipmi: SetPWM completion code 0x%02x", ccode)
+	}
+	return nil
+}
+
+func (g *GenericClient) RestoreAutoControl() error {
+	_, ccode, err := g.t.sendIPMI(0x2e, 0x30, []byte{0x01, 0x01})
+	if err != nil {
+		return err
+	}
+	if ccode != 0 {
+		return fmt.Errorf("ipmi: RestoreAutoControl completion code 0x%02x", ccode)
+	}
+	return nil
+}
+
+func (g *GenericClient) GetPWMs() (map[string]int, error) {
+	resp, ccode, err := g.t.sendIPMI(0x2e, 0x31, nil)
+	if err != nil {
+		return nil, err
+	}
+	if ccode != 0 {
+		return nil, fmt.Errorf("ipmi: GetPWMs completion code 0x%02x", ccode)
+	}
+
+	// As in IpmitoolClient.GetPWMs, the first response byte precedes the
+	// six per-fan PWM bytes.
+	pwms := make(map[string]int)
+	if len(resp) < len(FanNames)+1 {
+		return pwms, nil
+	}
+	for i, name := range FanNames {
+		pwms[name] = int(resp[i+1])
+	}
+	return pwms, nil
+}
+
+// sdrSensor pairs a decoded SDR record with its current converted reading
+// and the discrete state bits from the same Get Sensor Reading response.
+type sdrSensor struct {
+	record    fullSensorRecord
+	value     float64
+	stateBits byte
+}
+
+// walkSDRs reserves the SDR repository, iterates every record via Get SDR,
+// decodes the Full Sensor Records this client supports, and fetches each
+// one's current reading.
+func (g *GenericClient) walkSDRs() ([]sdrSensor, error) {
+	resResp, ccode, err := g.t.sendIPMI(0x0a, 0x22, nil) // Reserve SDR Repository
+	if err != nil {
+		return nil, err
+	}
+	if ccode != 0 || len(resResp) < 2 {
+		return nil, fmt.Errorf("ipmi: Reserve SDR Repository failed, completion code 0x%02x", ccode)
+	}
+	reservationID := resResp[:2]
+
+	var sensors []sdrSensor
+	recordID := uint16(0)
+	for {
+		req := append(append([]byte{}, reservationID...), byte(recordID), byte(recordID>>8), 0x00, 0xff)
+		resp, ccode, err := g.t.sendIPMI(0x0a, 0x23, req) // Get SDR
+		if err != nil {
+			return nil, err
+		}
+		if ccode != 0 || len(resp) < 3 {
+			break
+		}
+
+		nextRecordID := binary.LittleEndian.Uint16(resp[:2])
+		record := resp[2:]
+		if fsr, ok := parseFullSensorRecord(record); ok {
+			raw, stateBits, err := g.getSensorReading(fsr.sensorNumber)
+			if err == nil {
+				sensors = append(sensors, sdrSensor{record: fsr, value: linearConvert(fsr, raw), stateBits: stateBits})
+			}
+		}
+
+		if nextRecordID == 0xffff || nextRecordID == recordID {
+			break
+		}
+		recordID = nextRecordID
+	}
+	return sensors, nil
+}
+
+// getSensorReading issues Get Sensor Reading (netFn 0x04, cmd 0x2d) for a
+// single sensor number and returns its raw reading byte along with byte 3
+// of the response (the sensor's discrete/event state bits, Table 42-3),
+// used by PSU sensors to report presence and failure.
+func (g *GenericClient) getSensorReading(sensorNumber byte) (raw, stateBits byte, err error) {
+	resp, ccode, err := g.t.sendIPMI(0x04, 0x2d, []byte{sensorNumber})
+	if err != nil {
+		return 0, 0, err
+	}
+	if ccode != 0 || len(resp) < 1 {
+		return 0, 0, fmt.Errorf("ipmi: Get Sensor Reading failed, completion code 0x%02x", ccode)
+	}
+	if len(resp) >= 3 {
+		stateBits = resp[2]
+	}
+	return resp[0], stateBits, nil
+}
+
+// SensorList renders the SDR-derived sensor readings as pipe-delimited
+// lines shaped like `ipmitool sensor list`, so the shared parseSensorList
+// parser (and everything built on it - status, daemon, exporter) works
+// unchanged regardless of which Client implementation is in use. Only fan
+// and temperature sensor records are rendered; other sensor types, such as
+// airflow, are left out.
+func (g *GenericClient) SensorList() (string, error) {
+	sensors, err := g.walkSDRs()
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for _, s := range sensors {
+		if s.record.sensorType != sensorTypeFan && s.record.sensorType != sensorTypeTemperature {
+			continue
+		}
+		fmt.Fprintf(&b, "%-16s | %-10.3f | %-10s | ok | na | na | na | na | na | na\n",
+			s.record.name, s.value, s.record.unit)
+	}
+	return b.String(), nil
+}
+
+func (g *GenericClient) ChassisStatus() (string, error) {
+	resp, ccode, err := g.t.sendIPMI(0x00, 0x01, nil) // Get Chassis Status
+	if err != nil {
+		return "", err
+	}
+	if ccode != 0 || len(resp) < 1 {
+		return "", fmt.Errorf("ipmi: Get Chassis Status failed, completion code 0x%02x", ccode)
+	}
+
+	powerOn := resp[0]&0x01 != 0
+	overload := resp[0]&0x02 != 0
+	intrusion, driveFault, coolingFault := false, false, false
+	if len(resp) >= 3 {
+		intrusion = resp[2]&0x01 != 0
+		driveFault = resp[2]&0x04 != 0
+		coolingFault = resp[2]&0x08 != 0
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "System Power         : %s\n", onOff(powerOn))
+	fmt.Fprintf(&b, "Power Overload       : %v\n", overload)
+	fmt.Fprintf(&b, "Chassis Intrusion    : %s\n", trueFalseState(intrusion))
+	fmt.Fprintf(&b, "Drive Fault          : %v\n", driveFault)
+	fmt.Fprintf(&b, "Cooling/Fan Fault    : %v\n", coolingFault)
+	return b.String(), nil
+}
+
+func (g *GenericClient) PowerSupplySDR() (string, error) {
+	sensors, err := g.walkSDRs()
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for _, s := range sensors {
+		if s.record.sensorType != sensorTypePowerSupply {
+			continue
+		}
+		fmt.Fprintf(&b, "%-16s | %-10.3f | %-10s | %s\n", s.record.name, s.value, s.record.unit, psuSensorStatus(s.stateBits))
+	}
+	return b.String(), nil
+}
+
+// PSU discrete reading state bits (IPMI Table 42-3, "Power Supply" sensor
+// type), as returned in byte 3 of Get Sensor Reading.
+const (
+	psuStatePresent      = 0x01
+	psuStateFailure      = 0x02
+	psuStatePredictive   = 0x04
+	psuStateInputLost    = 0x08
+	psuStateInputOOR     = 0x10 // input lost or out-of-range
+	psuStateInputOORPres = 0x20 // input out-of-range, but present
+)
+
+// psuSensorStatus renders a PSU sensor's discrete state bits as the same
+// "ok"/"cr"/"na" status strings ipmitool's threshold sensors use, so
+// parsePSUStatuses (health.go) can derive PSU health the same way
+// regardless of which Client implementation produced the reading.
+func psuSensorStatus(stateBits byte) string {
+	if stateBits&psuStatePresent == 0 {
+		return "na"
+	}
+	if stateBits&(psuStateFailure|psuStatePredictive|psuStateInputLost|psuStateInputOOR|psuStateInputOORPres) != 0 {
+		return "cr"
+	}
+	return "ok"
+}
+
+func onOff(on bool) string {
+	if on {
+		return "on"
+	}
+	return "off"
+}
+
+func trueFalseState(v bool) string {
+	if v {
+		return "true"
+	}
+	return "false"
+}
@@ -0,0 +1,65 @@
+package ipmi
+
+import (
+	"crypto/hmac"
+	"crypto/subtle"
+	"encoding/binary"
+	"fmt"
+	"hash"
+)
+
+// buildIPMIRequestPayload builds an IPMI LAN request message: the two
+// header/checksum pairs the spec calls "first part" and "second part",
+// wrapping netFn/cmd/data for delivery to the BMC (address 0x20).
+func buildIPMIRequestPayload(netFn, cmd byte, data []byte, rqSeq byte) []byte {
+	head := []byte{bmcAddr, netFn << 2}
+	csum1 := ipmiChecksum(head)
+
+	body := []byte{consoleAddr, rqSeq << 2, cmd}
+	body = append(body, data...)
+	csum2 := ipmiChecksum(body)
+
+	full := append([]byte{}, head...)
+	full = append(full, csum1)
+	full = append(full, body...)
+	full = append(full, csum2)
+	return full
+}
+
+// parseIPMIResponsePayload unwraps an IPMI LAN response message, returning
+// the echoed command, the completion code, and any response data.
+func parseIPMIResponsePayload(payload []byte) (cmd, completionCode byte, data []byte, err error) {
+	if len(payload) < 7 {
+		return 0, 0, nil, fmt.Errorf("ipmi: response payload too short (%d bytes)", len(payload))
+	}
+	cmd = payload[5]
+	completionCode = payload[6]
+	if len(payload) > 8 {
+		data = append([]byte(nil), payload[7:len(payload)-1]...)
+	}
+	return cmd, completionCode, data, nil
+}
+
+// uint32ToBytes little-endian encodes a session ID for inclusion in an HMAC
+// input, as the RAKP+ spec requires.
+func uint32ToBytes(v uint32) []byte {
+	b := make([]byte, 4)
+	binary.LittleEndian.PutUint32(b, v)
+	return b
+}
+
+// hmacConcat computes HMAC(key)(concat(parts...)) for the various RAKP+
+// Key Exchange Authentication Codes, which are all HMACs over a
+// concatenation of several fields.
+func hmacConcat(hasher func() hash.Hash, key []byte, parts ...[]byte) []byte {
+	mac := hmac.New(hasher, key)
+	for _, p := range parts {
+		mac.Write(p)
+	}
+	return mac.Sum(nil)
+}
+
+// hmacEqual does a constant-time comparison of two MACs.
+func hmacEqual(a, b []byte) bool {
+	return subtle.ConstantTimeCompare(a, b) == 1
+}
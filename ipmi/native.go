@@ -0,0 +1,293 @@
+package ipmi
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+const (
+	bmcAddr     = 0x20
+	consoleAddr = 0x81
+	defaultPort = 623
+
+	// ipmiCallTimeout is the per-call read deadline for every round trip,
+	// including the session-establishment handshake. IPMI-over-UDP routinely drops
+	// packets, so a timed-out read is retried rather than treated as fatal.
+	ipmiCallTimeout = 5 * time.Second
+	ipmiMaxRetries  = 3
+)
+
+// netTransport is a pure-Go implementation of the RMCP+ / IPMI 2.0 LAN
+// transport: RAKP+ session establishment followed by AES-CBC-128 encrypted,
+// HMAC integrity-protected IPMI request/response packets over UDP.
+//
+// Scope: this targets the common "one-key logins" deployment (no separate
+// BMC key Kg, i.e. Kg defaults to the user's password), which covers the
+// overwhelming majority of server BMCs including this board's. Multi-key
+// (Kg != password) setups are not supported.
+type netTransport struct {
+	conn *net.UDPConn
+
+	authAlgo      byte
+	integrityAlgo byte
+	confAlgo      byte
+
+	bmcSessionID     uint32
+	consoleSessionID uint32
+	k1, k2           []byte
+
+	seq   uint32 // IPMI 2.0 session sequence number
+	rqSeq byte   // per-message sequence, wraps at 64 per spec
+}
+
+// dialNative establishes a full RAKP+ session with a remote BMC over
+// UDP/623 (or the given port).
+func dialNative(host string, port int, username, password string) (*netTransport, error) {
+	if port == 0 {
+		port = defaultPort
+	}
+	addr := fmt.Sprintf("%s:%d", host, port)
+	udpAddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("ipmi: resolving %s: %w", addr, err)
+	}
+	conn, err := net.DialUDP("udp", nil, udpAddr)
+	if err != nil {
+		return nil, fmt.Errorf("ipmi: dialing %s: %w", addr, err)
+	}
+
+	t := &netTransport{conn: conn}
+	if err := t.establishSession(username, password); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return t, nil
+}
+
+// NewNativeClient opens a session to a remote BMC over RMCP+/IPMI-2.0-LAN
+// and returns a Client backed entirely by this pure-Go transport, with no
+// dependency on the ipmitool binary.
+func NewNativeClient(host string, port int, username, password string) (*GenericClient, error) {
+	t, err := dialNative(host, port, username, password)
+	if err != nil {
+		return nil, err
+	}
+	return &GenericClient{t: t}, nil
+}
+
+func (t *netTransport) establishSession(username, password string) error {
+	consoleSessionID := uint32(time.Now().UnixNano())
+
+	openReq := buildOpenSessionRequest(0x01, consoleSessionID, AuthAlgoRAKPHMACSHA1, IntegrityAlgoHMACSHA1_96, ConfAlgoAESCBC128)
+	respPayload, err := t.roundTripUnauthenticated(payloadTypeOpenSessionRequest, openReq, payloadTypeOpenSessionResponse)
+	if err != nil {
+		return fmt.Errorf("ipmi: open session request: %w", err)
+	}
+	osr, err := parseOpenSessionResponse(respPayload)
+	if err != nil {
+		return err
+	}
+	t.bmcSessionID = osr.bmcSessionID
+	t.consoleSessionID = osr.consoleSessionID
+	t.authAlgo = osr.authAlgo
+	t.integrityAlgo = osr.integrityAlgo
+	t.confAlgo = osr.confAlgo
+
+	var consoleRand [16]byte
+	if _, err := rand.Read(consoleRand[:]); err != nil {
+		return err
+	}
+
+	rakp1 := buildRAKP1(0x01, t.bmcSessionID, consoleRand, username)
+	rakp2Payload, err := t.roundTripUnauthenticated(payloadTypeRAKP1, rakp1, payloadTypeRAKP2)
+	if err != nil {
+		return fmt.Errorf("ipmi: RAKP1: %w", err)
+	}
+	rakp2, err := parseRAKP2(rakp2Payload)
+	if err != nil {
+		return err
+	}
+
+	hasher, err := hasherFor(t.authAlgo)
+	if err != nil {
+		return err
+	}
+
+	sidBytes := uint32ToBytes(t.consoleSessionID)
+	expectedAuthCode := hmacConcat(hasher, []byte(password),
+		sidBytes, uint32ToBytes(t.bmcSessionID), consoleRand[:], rakp2.bmcRand[:], rakp2.bmcGUID[:],
+		[]byte{privilegeLevelAdministrator, byte(len(username))}, []byte(username))
+	if !hmacEqual(expectedAuthCode, rakp2.authCode) {
+		return fmt.Errorf("ipmi: RAKP2 authentication code mismatch (wrong username/password?)")
+	}
+
+	sik := deriveSIK(hasher, []byte(password), consoleRand, rakp2.bmcRand, privilegeLevelAdministrator, username)
+	t.k1, t.k2 = deriveK1K2(hasher, sik)
+
+	rakp3AuthCode := hmacConcat(hasher, []byte(password),
+		rakp2.bmcRand[:], sidBytes, []byte{privilegeLevelAdministrator, byte(len(username))}, []byte(username))
+	rakp3 := buildRAKP3(0x01, 0x00, t.bmcSessionID, rakp3AuthCode)
+	rakp4Payload, err := t.roundTripUnauthenticated(payloadTypeRAKP3, rakp3, payloadTypeRAKP4)
+	if err != nil {
+		return fmt.Errorf("ipmi: RAKP3: %w", err)
+	}
+	if err := parseRAKP4(rakp4Payload); err != nil {
+		return err
+	}
+
+	t.seq = 1
+	return nil
+}
+
+// roundTripUnauthenticated sends one of the pre-session payloads (open
+// session request or RAKP 1/3) and waits for its matching response payload
+// type. These packets are never encrypted or integrity-protected - the
+// session doesn't exist yet.
+func (t *netTransport) roundTripUnauthenticated(payloadType byte, payload []byte, wantPayloadType byte) ([]byte, error) {
+	pkt := append(rmcpHeader(), sessionHeader(payloadType, false, false, 0, 0, payload)...)
+
+	buf := make([]byte, 1024)
+	var lastErr error
+	for attempt := 0; attempt < ipmiMaxRetries; attempt++ {
+		if _, err := t.conn.Write(pkt); err != nil {
+			return nil, err
+		}
+		if err := t.conn.SetDeadline(time.Now().Add(ipmiCallTimeout)); err != nil {
+			return nil, err
+		}
+		n, err := t.conn.Read(buf)
+		if err != nil {
+			if isTimeout(err) {
+				lastErr = err
+				continue
+			}
+			return nil, err
+		}
+		return parseSessionWrapper(buf[:n], wantPayloadType)
+	}
+	return nil, fmt.Errorf("ipmi: no response after %d attempts: %w", ipmiMaxRetries, lastErr)
+}
+
+// isTimeout reports whether err is a network timeout, as opposed to a
+// fatal connection error.
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// parseSessionWrapper strips the RMCP and IPMI 2.0 session headers from a
+// received packet, returning the inner payload.
+func parseSessionWrapper(pkt []byte, wantPayloadType byte) ([]byte, error) {
+	if len(pkt) < 4+12 {
+		return nil, fmt.Errorf("ipmi: packet too short (%d bytes)", len(pkt))
+	}
+	body := pkt[4:] // skip RMCP header
+	payloadType := body[1] & 0x3f
+	if payloadType != wantPayloadType {
+		return nil, fmt.Errorf("ipmi: unexpected payload type 0x%02x, wanted 0x%02x", payloadType, wantPayloadType)
+	}
+	length := int(body[10]) | int(body[11])<<8
+	if len(body) < 12+length {
+		return nil, fmt.Errorf("ipmi: truncated payload (want %d, have %d)", length, len(body)-12)
+	}
+	return body[12 : 12+length], nil
+}
+
+func (t *netTransport) sendIPMI(netFn, cmd byte, data []byte) ([]byte, byte, error) {
+	t.rqSeq = (t.rqSeq + 1) % 64
+	req := buildIPMIRequestPayload(netFn, cmd, data, t.rqSeq)
+
+	plaintext := req
+	encrypted := t.confAlgo != ConfAlgoNone
+	var payload []byte
+	if encrypted {
+		enc, err := aesCBCEncrypt(t.k2, plaintext)
+		if err != nil {
+			return nil, 0, err
+		}
+		payload = enc
+	} else {
+		payload = plaintext
+	}
+
+	t.seq++
+	header := sessionHeader(payloadTypeIPMI, encrypted, true, t.bmcSessionID, t.seq, payload)
+
+	pad, padLen := integrityPad(len(header))
+	trailer := append(append([]byte{}, pad...), padLen, 0x07)
+	toSign := append(append([]byte{}, header...), trailer...)
+	tag, err := integrityTag(t.integrityAlgo, t.k1, toSign)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	pkt := append(rmcpHeader(), toSign...)
+	pkt = append(pkt, tag...)
+
+	buf := make([]byte, 2048)
+	var n int
+	var lastErr error
+	for attempt := 0; attempt < ipmiMaxRetries; attempt++ {
+		if _, err := t.conn.Write(pkt); err != nil {
+			return nil, 0, err
+		}
+		if err := t.conn.SetDeadline(time.Now().Add(ipmiCallTimeout)); err != nil {
+			return nil, 0, err
+		}
+		n, err = t.conn.Read(buf)
+		if err != nil {
+			if isTimeout(err) {
+				lastErr = err
+				continue
+			}
+			return nil, 0, err
+		}
+		lastErr = nil
+		break
+	}
+	if lastErr != nil {
+		return nil, 0, fmt.Errorf("ipmi: no response after %d attempts: %w", ipmiMaxRetries, lastErr)
+	}
+
+	respPayload, err := parseAuthenticatedResponse(buf[:n], t.integrityAlgo, t.confAlgo, t.k2)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	_, ccode, data2, err := parseIPMIResponsePayload(respPayload)
+	if err != nil {
+		return nil, 0, err
+	}
+	return data2, ccode, nil
+}
+
+// parseAuthenticatedResponse strips RMCP/session headers and the integrity
+// trailer from a post-RAKP packet, decrypting the payload if necessary.
+func parseAuthenticatedResponse(pkt []byte, integrityAlgo, confAlgo byte, k2 []byte) ([]byte, error) {
+	if len(pkt) < 16 {
+		return nil, fmt.Errorf("ipmi: response packet too short (%d bytes)", len(pkt))
+	}
+	body := pkt[4:]
+	length := int(body[10]) | int(body[11])<<8
+	if len(body) < 12+length {
+		return nil, fmt.Errorf("ipmi: truncated response payload")
+	}
+	payload := body[12 : 12+length]
+
+	if confAlgo != ConfAlgoNone {
+		plain, err := aesCBCDecrypt(k2, payload)
+		if err != nil {
+			return nil, err
+		}
+		return plain, nil
+	}
+	return payload, nil
+}
+
+func (t *netTransport) close() error {
+	_, _, _ = t.sendIPMI(0x06, 0x3c, uint32ToBytes(t.bmcSessionID))
+	return t.conn.Close()
+}
@@ -0,0 +1,171 @@
+//go:build linux
+
+package ipmi
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// devTransport talks to the BMC over the local system interface via the
+// kernel's OpenIPMI character device (/dev/ipmi0), using the same ioctl
+// ABI ipmitool's "open" interface uses. This lets rd450x-fan-control run
+// without any IPMI userspace tooling installed at all when it's running
+// on the host itself rather than against a remote BMC.
+type devTransport struct {
+	f     *os.File
+	msgID int64
+}
+
+const ipmiSystemInterfaceAddrType = 0x0c
+
+// ipmiSystemInterfaceAddr mirrors struct ipmi_system_interface_addr from
+// <linux/ipmi.h>.
+type ipmiSystemInterfaceAddr struct {
+	addrType int32
+	channel  int16
+	lun      uint8
+	_        uint8
+}
+
+// ipmiMsg mirrors struct ipmi_msg from <linux/ipmi.h>.
+type ipmiMsg struct {
+	netfn   uint8
+	cmd     uint8
+	dataLen uint16
+	_       uint32 // padding before the pointer field, to match the C layout
+	data    uintptr
+}
+
+// ipmiReq mirrors struct ipmi_req from <linux/ipmi.h>.
+type ipmiReq struct {
+	addr    uintptr
+	addrLen uint32
+	_       uint32
+	msgid   int64
+	msg     ipmiMsg
+}
+
+// ipmiRecv mirrors struct ipmi_recv from <linux/ipmi.h>.
+type ipmiRecv struct {
+	recvType int32
+	_        uint32
+	addr     uintptr
+	addrLen  uint32
+	_        uint32
+	msgid    int64
+	msg      ipmiMsg
+}
+
+const (
+	ipmiIOCMagic = 'i'
+	iocNone      = 0
+	iocWrite     = 1
+	iocRead      = 2
+)
+
+func ioc(dir, typ, nr uint32, size uintptr) uintptr {
+	return uintptr(dir<<30 | typ<<8 | nr | uint32(size)<<16)
+}
+
+var (
+	ipmictlSendCommand     = ioc(iocRead, ipmiIOCMagic, 13, unsafe.Sizeof(ipmiReq{}))
+	ipmictlReceiveMsgTrunc = ioc(iocRead|iocWrite, ipmiIOCMagic, 11, unsafe.Sizeof(ipmiRecv{}))
+)
+
+func ioctl(fd, req, arg uintptr) error {
+	_, _, errno := unix.Syscall(unix.SYS_IOCTL, fd, req, arg)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// dialDevice opens the local IPMI device. devicePath defaults to
+// /dev/ipmi0 if empty.
+func dialDevice(devicePath string) (*devTransport, error) {
+	if devicePath == "" {
+		devicePath = "/dev/ipmi0"
+	}
+	f, err := os.OpenFile(devicePath, os.O_RDWR, 0)
+	if err != nil {
+		return nil, fmt.Errorf("ipmi: opening %s: %w", devicePath, err)
+	}
+	return &devTransport{f: f}, nil
+}
+
+// NewOpenClient returns a Client backed by the local BMC device
+// (/dev/ipmi0 by default), with no dependency on the ipmitool binary -
+// intended for running natively on the chassis itself, including inside a
+// container that has the device bind-mounted in.
+func NewOpenClient(devicePath string) (*GenericClient, error) {
+	d, err := dialDevice(devicePath)
+	if err != nil {
+		return nil, err
+	}
+	return &GenericClient{t: d}, nil
+}
+
+func (d *devTransport) sendIPMI(netFn, cmd byte, data []byte) ([]byte, byte, error) {
+	d.msgID++
+
+	addr := ipmiSystemInterfaceAddr{addrType: ipmiSystemInterfaceAddrType}
+	var dataPtr uintptr
+	if len(data) > 0 {
+		dataPtr = uintptr(unsafe.Pointer(&data[0]))
+	}
+
+	req := ipmiReq{
+		addr:    uintptr(unsafe.Pointer(&addr)),
+		addrLen: uint32(unsafe.Sizeof(addr)),
+		msgid:   d.msgID,
+		// Unlike the LAN wire format (buildIPMIRequestPayload), the
+		// OpenIPMI ioctl ABI takes the raw, unshifted netFn.
+		msg: ipmiMsg{netfn: netFn, cmd: cmd, dataLen: uint16(len(data)), data: dataPtr},
+	}
+	err := ioctl(d.f.Fd(), ipmictlSendCommand, uintptr(unsafe.Pointer(&req)))
+	// addr and data are only referenced through raw uintptrs stashed in req,
+	// which the GC can't see - keep them alive until the ioctl that
+	// dereferences them has returned.
+	runtime.KeepAlive(&addr)
+	runtime.KeepAlive(data)
+	if err != nil {
+		return nil, 0, fmt.Errorf("ipmi: IPMICTL_SEND_COMMAND: %w", err)
+	}
+
+	respBuf := make([]byte, 256)
+	var respAddr ipmiSystemInterfaceAddr
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		recv := ipmiRecv{
+			addr:    uintptr(unsafe.Pointer(&respAddr)),
+			addrLen: uint32(unsafe.Sizeof(respAddr)),
+			msg:     ipmiMsg{dataLen: uint16(len(respBuf)), data: uintptr(unsafe.Pointer(&respBuf[0]))},
+		}
+		err := ioctl(d.f.Fd(), ipmictlReceiveMsgTrunc, uintptr(unsafe.Pointer(&recv)))
+		// respAddr and respBuf are only referenced through raw uintptrs
+		// stashed in recv - same GC-visibility concern as the send side.
+		runtime.KeepAlive(&respAddr)
+		runtime.KeepAlive(respBuf)
+		if err == nil {
+			n := int(recv.msg.dataLen)
+			if n < 1 {
+				return nil, 0, fmt.Errorf("ipmi: empty response from local BMC device")
+			}
+			return append([]byte(nil), respBuf[1:n]...), respBuf[0], nil
+		}
+		if time.Now().After(deadline) {
+			return nil, 0, fmt.Errorf("ipmi: timed out waiting for response from local BMC device: %w", err)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func (d *devTransport) close() error {
+	return d.f.Close()
+}
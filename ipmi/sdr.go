@@ -0,0 +1,126 @@
+package ipmi
+
+import "strings"
+
+// fullSensorRecord holds the fields of an SDR "Full Sensor Record" (type
+// 0x01) needed to convert a raw sensor reading into an engineering-unit
+// value. Only the linear conversion (linearization byte 0x00) is
+// implemented, since every sensor on this chassis (fan RPM, temperature,
+// airflow, power) reports linear data; non-linear sensors are skipped.
+type fullSensorRecord struct {
+	sensorNumber byte
+	sensorType   byte
+	name         string
+	unit         string
+	linear       bool
+	m            int32
+	b            int32
+	rExp         int32
+	bExp         int32
+}
+
+// sensorTypeFan / sensorTypeTemperature / sensorTypePowerSupply are the IPMI
+// Sensor Type codes (Table 42-3) this client cares about.
+const (
+	sensorTypeTemperature = 0x01
+	sensorTypeFan         = 0x04
+	sensorTypePowerSupply = 0x08
+)
+
+// sign10 sign-extends a 10-bit two's complement value.
+func sign10(v uint16) int32 {
+	v &= 0x3ff
+	if v&0x200 != 0 {
+		return int32(v) - 0x400
+	}
+	return int32(v)
+}
+
+// sign4 sign-extends a 4-bit two's complement value.
+func sign4(v byte) int32 {
+	v &= 0x0f
+	if v&0x08 != 0 {
+		return int32(v) - 0x10
+	}
+	return int32(v)
+}
+
+// parseFullSensorRecord decodes an SDR Full Sensor Record, including the
+// header, as returned by the Get SDR command. Returns ok=false if the
+// record is not a full sensor record, is truncated, or uses a non-linear
+// linearization this client doesn't support.
+func parseFullSensorRecord(record []byte) (fullSensorRecord, bool) {
+	var fsr fullSensorRecord
+	if len(record) < 48 {
+		return fsr, false
+	}
+	if record[3] != 0x01 { // Record Type: Full Sensor Record
+		return fsr, false
+	}
+
+	fsr.sensorNumber = record[7]
+	fsr.sensorType = record[12]
+
+	linearization := record[23] & 0x7f
+	if linearization != 0x00 {
+		return fsr, false // non-linear sensor, unsupported
+	}
+	fsr.linear = true
+
+	m := uint16(record[24]) | uint16(record[25]&0xc0)<<2
+	fsr.m = sign10(m)
+	b := uint16(record[26]) | uint16(record[27]&0xc0)<<2
+	fsr.b = sign10(b)
+	fsr.rExp = sign4(record[29] >> 4)
+	fsr.bExp = sign4(record[29])
+
+	fsr.unit = sensorUnitString(record[21])
+
+	idTypeLen := record[47]
+	idLen := int(idTypeLen & 0x1f)
+	nameStart := 48
+	if nameStart+idLen <= len(record) {
+		fsr.name = strings.TrimRight(string(record[nameStart:nameStart+idLen]), "\x00")
+	}
+
+	return fsr, true
+}
+
+// sensorUnitString maps an SDR "Base Unit" byte (Table 43-15) to the
+// subset of units this chassis actually reports.
+func sensorUnitString(baseUnit byte) string {
+	switch baseUnit {
+	case 1:
+		return "degrees C"
+	case 18:
+		return "RPM"
+	case 40:
+		return "CFM"
+	case 6:
+		return "Watts"
+	default:
+		return ""
+	}
+}
+
+// pow10 is a tiny integer power-of-ten helper; SDR exponents are always
+// small (-8..7), so this avoids pulling in math.Pow for float semantics.
+func pow10(exp int32) float64 {
+	result := 1.0
+	if exp >= 0 {
+		for i := int32(0); i < exp; i++ {
+			result *= 10
+		}
+		return result
+	}
+	for i := int32(0); i > exp; i-- {
+		result /= 10
+	}
+	return result
+}
+
+// linearConvert applies the standard IPMI linear sensor formula:
+// y = (M*raw + B*10^bExp) * 10^rExp
+func linearConvert(fsr fullSensorRecord, raw byte) float64 {
+	return (float64(fsr.m)*float64(raw) + float64(fsr.b)*pow10(fsr.bExp)) * pow10(fsr.rExp)
+}
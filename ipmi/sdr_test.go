@@ -0,0 +1,75 @@
+package ipmi
+
+import "testing"
+
+// buildTestFullSensorRecord constructs a minimal but valid SDR Full Sensor
+// Record byte stream for a linear sensor with the given M, B, Rexp, Bexp
+// and name, to exercise parseFullSensorRecord/linearConvert without a real
+// BMC.
+func buildTestFullSensorRecord(name string, sensorType byte, unit byte, m, b int32, rExp, bExp int32) []byte {
+	record := make([]byte, 48+len(name))
+	record[3] = 0x01 // Record Type: Full Sensor Record
+	record[7] = 0x05 // sensor number
+	record[12] = sensorType
+	record[21] = unit
+	record[23] = 0x00 // linearization: linear
+
+	mu := uint16(m) & 0x3ff
+	record[24] = byte(mu)
+	record[25] = byte((mu >> 2) & 0xc0)
+
+	bu := uint16(b) & 0x3ff
+	record[26] = byte(bu)
+	record[27] = byte((bu >> 2) & 0xc0)
+
+	record[29] = byte((rExp&0x0f)<<4) | byte(bExp&0x0f)
+
+	record[47] = byte(len(name)) // ID string type/length: length-only encoding for this test
+	copy(record[48:], name)
+
+	return record
+}
+
+func TestParseFullSensorRecordAndLinearConvert(t *testing.T) {
+	record := buildTestFullSensorRecord("CPU Fan1", sensorTypeFan, 18, 10, 0, 0, 0)
+
+	fsr, ok := parseFullSensorRecord(record)
+	if !ok {
+		t.Fatal("parseFullSensorRecord returned ok=false for a valid linear record")
+	}
+	if fsr.name != "CPU Fan1" {
+		t.Errorf("name = %q, want %q", fsr.name, "CPU Fan1")
+	}
+	if fsr.unit != "RPM" {
+		t.Errorf("unit = %q, want RPM", fsr.unit)
+	}
+	if fsr.m != 10 || fsr.b != 0 {
+		t.Errorf("m=%d b=%d, want m=10 b=0", fsr.m, fsr.b)
+	}
+
+	got := linearConvert(fsr, 120)
+	if got != 1200 {
+		t.Errorf("linearConvert(raw=120) = %v, want 1200 (M=10 * raw=120)", got)
+	}
+}
+
+func TestParseFullSensorRecordRejectsNonLinear(t *testing.T) {
+	record := buildTestFullSensorRecord("Weird Sensor", sensorTypeTemperature, 1, 1, 0, 0, 0)
+	record[23] = 0x08 // non-linear linearization type
+
+	if _, ok := parseFullSensorRecord(record); ok {
+		t.Error("expected parseFullSensorRecord to reject a non-linear record")
+	}
+}
+
+func TestSign10AndSign4(t *testing.T) {
+	if got := sign10(0x3ff); got != -1 {
+		t.Errorf("sign10(0x3ff) = %d, want -1", got)
+	}
+	if got := sign10(0x001); got != 1 {
+		t.Errorf("sign10(0x001) = %d, want 1", got)
+	}
+	if got := sign4(0x0f); got != -1 {
+		t.Errorf("sign4(0x0f) = %d, want -1", got)
+	}
+}
@@ -0,0 +1,12 @@
+package ipmi
+
+// transport is the minimum a BMC connection needs to provide: the ability
+// to send one IPMI request and get back its completion code and data.
+// netTransport (RMCP+/LAN, native.go) and devTransport (/dev/ipmi0 ioctl,
+// open_linux.go) both implement it; GenericClient builds the rest of the
+// Client interface (SDR walking, OEM fan commands, formatting) on top of
+// whichever one is in use.
+type transport interface {
+	sendIPMI(netFn, cmd byte, data []byte) (respData []byte, completionCode byte, err error)
+	close() error
+}
@@ -0,0 +1,116 @@
+// Package logger provides a small leveled logger with optional file
+// rotation, used throughout rd450x-fan-control in place of ad-hoc
+// fmt.Println error reporting.
+package logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// Level is a logging severity, ordered least to most severe.
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// ParseLevel parses a level name (case-insensitive); it defaults to Info
+// for an empty or unrecognized string.
+func ParseLevel(s string) Level {
+	switch strings.ToUpper(s) {
+	case "DEBUG":
+		return LevelDebug
+	case "WARN", "WARNING":
+		return LevelWarn
+	case "ERROR":
+		return LevelError
+	default:
+		return LevelInfo
+	}
+}
+
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	default:
+		return "INFO"
+	}
+}
+
+// Config controls where a Logger writes and how aggressively it rotates
+// its log file. A zero Config logs to stderr at Info level with no
+// rotation.
+type Config struct {
+	Level      string `yaml:"level"`
+	File       string `yaml:"file"`
+	MaxSizeMB  int    `yaml:"max_size_mb"`
+	MaxAgeDays int    `yaml:"max_age_days"`
+	MaxBackups int    `yaml:"max_backups"`
+}
+
+// Logger writes leveled, timestamped lines to its configured output.
+type Logger struct {
+	level Level
+	out   io.Writer
+}
+
+// New builds a Logger from cfg. When cfg.File is empty, it logs to
+// stderr; otherwise it rotates the file at MaxSizeMB megabytes, keeping
+// MaxBackups old files for up to MaxAgeDays.
+func New(cfg Config) *Logger {
+	level := ParseLevel(cfg.Level)
+
+	if cfg.File == "" {
+		return &Logger{level: level, out: os.Stderr}
+	}
+
+	maxSize := cfg.MaxSizeMB
+	if maxSize <= 0 {
+		maxSize = 10
+	}
+	maxAge := cfg.MaxAgeDays
+	if maxAge <= 0 {
+		maxAge = 7
+	}
+	maxBackups := cfg.MaxBackups
+	if maxBackups <= 0 {
+		maxBackups = 5
+	}
+
+	return &Logger{
+		level: level,
+		out: &lumberjack.Logger{
+			Filename:   cfg.File,
+			MaxSize:    maxSize,
+			MaxAge:     maxAge,
+			MaxBackups: maxBackups,
+		},
+	}
+}
+
+func (l *Logger) log(level Level, format string, args ...interface{}) {
+	if l == nil || level < l.level {
+		return
+	}
+	msg := fmt.Sprintf(format, args...)
+	fmt.Fprintf(l.out, "%s %-5s %s\n", time.Now().UTC().Format(time.RFC3339), level, msg)
+}
+
+func (l *Logger) Debugf(format string, args ...interface{}) { l.log(LevelDebug, format, args...) }
+func (l *Logger) Infof(format string, args ...interface{})  { l.log(LevelInfo, format, args...) }
+func (l *Logger) Warnf(format string, args ...interface{})  { l.log(LevelWarn, format, args...) }
+func (l *Logger) Errorf(format string, args ...interface{}) { l.log(LevelError, format, args...) }
@@ -0,0 +1,41 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	cases := map[string]Level{
+		"debug": LevelDebug,
+		"WARN":  LevelWarn,
+		"Error": LevelError,
+		"":      LevelInfo,
+		"bogus": LevelInfo,
+	}
+	for in, want := range cases {
+		if got := ParseLevel(in); got != want {
+			t.Errorf("ParseLevel(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
+
+func TestLoggerFiltersBelowConfiguredLevel(t *testing.T) {
+	var buf bytes.Buffer
+	l := &Logger{level: LevelWarn, out: &buf}
+
+	l.Infof("should be filtered")
+	l.Warnf("should appear: %d", 42)
+
+	out := buf.String()
+	if strings.Contains(out, "filtered") {
+		t.Errorf("Infof line should have been filtered at Warn level, got: %q", out)
+	}
+	if !strings.Contains(out, "should appear: 42") {
+		t.Errorf("Warnf line missing from output: %q", out)
+	}
+	if !strings.Contains(out, "WARN") {
+		t.Errorf("expected level tag WARN in output: %q", out)
+	}
+}
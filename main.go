@@ -2,11 +2,16 @@ package main
 
 import (
 	"encoding/json"
+	"flag"
 	"fmt"
 	"os"
 	"os/exec"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/Vabavia/rd450x-fan-control/ipmi"
+	"github.com/Vabavia/rd450x-fan-control/logger"
 )
 
 // FanStatus represents fan telemetry data
@@ -26,102 +31,50 @@ type ThermalStatus struct {
 type StatusReport struct {
 	Fans     []FanStatus     `json:"fans"`
 	Thermals []ThermalStatus `json:"thermals"`
-}
-
-// ipmiRaw executes raw IPMI OEM commands
-func ipmiRaw(args ...string) (string, error) {
-	fullArgs := append([]string{"raw", "0x2e"}, args...)
-	cmd := exec.Command("ipmitool", fullArgs...)
-	out, err := cmd.CombinedOutput()
-	if err != nil {
-		return "", fmt.Errorf("%v: %s", err, string(out))
-	}
-	return string(out), nil
-}
-
-// hexToPercent converts a hex string (e.g., "32") to a percentage string ("50%")
-func hexToPercent(hexStr string) string {
-	dec, err := strconv.ParseInt(hexStr, 16, 64)
-	if err != nil {
-		return "N/A"
-	}
-	return fmt.Sprintf("%d%%", dec)
-}
-
-// getPWMs reads PWM percentages via undocumented OEM command 0x31
-func getPWMs() map[string]string {
-	pwms := make(map[string]string)
-
-	out, err := ipmiRaw("0x31")
-	if err != nil {
-		return pwms // Return empty map if command fails
-	}
-
-	parts := strings.Fields(out)
-	if len(parts) >= 7 {
-		pwms["System Fan1"] = hexToPercent(parts[1])
-		pwms["System Fan2"] = hexToPercent(parts[2])
-		pwms["System Fan3"] = hexToPercent(parts[3])
-		pwms["System Fan4"] = hexToPercent(parts[4])
-		pwms["CPU Fan1"] = hexToPercent(parts[5])
-		pwms["CPU Fan2"] = hexToPercent(parts[6])
-	}
-
-	return pwms
+	PSUs     []PSUStatus     `json:"psus"`
+	Health   HealthStatus    `json:"health"`
 }
 
 // getSpeed instantly fetches the PWM for a specific fan
-func getSpeed(idStr string) {
+func getSpeed(client ipmi.Client, idStr string, log *logger.Logger) {
 	if strings.ToLower(idStr) == "all" || idStr == "0" || idStr == "00" {
-		fmt.Println("Error: To view all fans, use the 'status' command.")
+		log.Errorf("To view all fans, use the 'status' command.")
 		return
 	}
 
 	id, err := strconv.Atoi(idStr)
 	if err != nil || id < 1 || id > 6 {
-		fmt.Println("Error: Fan ID must be between 01 and 06")
+		log.Errorf("Fan ID must be between 01 and 06")
 		return
 	}
 
-	out, err := ipmiRaw("0x31")
+	pwms, err := client.GetPWMs()
 	if err != nil {
-		fmt.Printf("IPMI Error: %v\n", err)
+		log.Errorf("IPMI error: %v", err)
 		os.Exit(1)
 	}
 
-	parts := strings.Fields(out)
-	if len(parts) > id {
-		pwm := hexToPercent(parts[id])
-		fmt.Printf("Fan %02d PWM: %s\n", id, pwm)
-	} else {
-		fmt.Println("Error: Unexpected IPMI response format or missing data")
+	pwm, ok := pwms[ipmi.FanNames[id-1]]
+	if !ok {
+		log.Errorf("Unexpected IPMI response format or missing data")
+		return
 	}
+	fmt.Printf("Fan %02d PWM: %d%%\n", id, pwm)
 }
 
 // setSpeed handles the fan speed adjustment logic
-func setSpeed(idStr, speedStr string) {
+func setSpeed(client ipmi.Client, idStr, speedStr string, log *logger.Logger) {
 	speed, err := strconv.Atoi(speedStr)
 	if err != nil || speed < 0 || speed > 100 {
-		fmt.Println("Error: Speed must be an integer between 0 and 100")
+		log.Errorf("Speed must be an integer between 0 and 100")
 		return
 	}
 
-	hexSpeed := fmt.Sprintf("0x%x", speed)
+	formattedID := ipmi.FormatFanID(idStr)
+	isAll := formattedID == "00"
 
-	formattedID := idStr
-	isAll := false
-
-	// Support for "all", "0", or "00" to set all fans at once
-	if strings.ToLower(idStr) == "all" || idStr == "0" || idStr == "00" {
-		formattedID = "00"
-		isAll = true
-	} else if len(idStr) == 1 {
-		formattedID = "0" + idStr
-	}
-
-	_, err = ipmiRaw("0x30", "00", formattedID, hexSpeed)
-	if err != nil {
-		fmt.Printf("IPMI Error: %v\n", err)
+	if err := client.SetPWM(formattedID, speed); err != nil {
+		log.Errorf("IPMI error: %v", err)
 		os.Exit(1)
 	}
 
@@ -133,75 +86,53 @@ func setSpeed(idStr, speedStr string) {
 }
 
 // getStatus fetches and displays full sensor telemetry
-func getStatus(asJson bool) {
-	pwms := getPWMs()
+func getStatus(client ipmi.Client, asJson bool, log *logger.Logger) {
+	pwms, err := client.GetPWMs()
+	if err != nil {
+		pwms = map[string]int{}
+	}
 
-	cmd := exec.Command("ipmitool", "sensor", "list")
-	out, err := cmd.Output()
+	raw, err := client.SensorList()
 	if err != nil {
-		fmt.Printf("Error fetching sensor data: %v\n", err)
+		log.Errorf("fetching sensor data: %v", err)
 		return
 	}
 
-	lines := strings.Split(string(out), "\n")
+	var psus []PSUStatus
+	if psuRaw, err := client.PowerSupplySDR(); err == nil {
+		psus = parsePSUStatuses(psuRaw)
+	}
+
+	var health HealthStatus
+	if chassisRaw, err := client.ChassisStatus(); err == nil {
+		health = parseHealthStatus(chassisRaw)
+	}
 
 	rpmMap := make(map[string]string)
 	var thermals []ThermalStatus
 
-	// 1. Parse sensor list to get RPMs and Thermals
-	for _, line := range lines {
-		if strings.TrimSpace(line) == "" {
-			continue
-		}
-
-		parts := strings.Split(line, "|")
-		if len(parts) < 3 {
-			continue
-		}
-
-		name := strings.TrimSpace(parts[0])
-		val := strings.TrimSpace(parts[1])
-		unit := strings.TrimSpace(parts[2])
-		lineUpper := strings.ToUpper(line)
-
-		if val == "na" && !strings.Contains(lineUpper, "FAN") {
-			continue // Skip disconnected thermal sensors
-		}
-
-		if strings.Contains(lineUpper, "FAN") && !strings.Contains(lineUpper, "POWER") {
-			if val != "na" {
-				rpmMap[name] = val + " RPM"
-			}
-		} else if strings.Contains(lineUpper, "TEMP") || strings.Contains(lineUpper, "AIRFLOW") {
-			valFloat, err := strconv.ParseFloat(val, 64)
-			cleanVal := val
-			if err == nil {
-				cleanVal = fmt.Sprintf("%.0f", valFloat)
+	for _, r := range parseSensorList(raw) {
+		nameUpper := strings.ToUpper(r.Name)
+		if r.IsFan {
+			rpmMap[r.Name] = fmt.Sprintf("%.0f RPM", r.Value)
+		} else if strings.Contains(nameUpper, "TEMP") || strings.Contains(nameUpper, "AIRFLOW") {
+			unit := r.Unit
+			if unit == "degrees C" {
+				unit = "°C"
 			}
-
-			cleanUnit := unit
-			if cleanUnit == "degrees C" {
-				cleanUnit = "°C"
-			}
-
-			if cleanVal == "0" && cleanUnit == "°C" {
-				continue
-			}
-
 			thermals = append(thermals, ThermalStatus{
-				Name:  name,
-				Value: fmt.Sprintf("%s %s", cleanVal, cleanUnit),
+				Name:  r.Name,
+				Value: fmt.Sprintf("%.0f %s", r.Value, unit),
 			})
 		}
 	}
 
-	// 2. Build the exact 6-fan array
-	fanNames := []string{"System Fan1", "System Fan2", "System Fan3", "System Fan4", "CPU Fan1", "CPU Fan2"}
+	// Build the exact 6-fan array
 	var fans []FanStatus
-	for _, name := range fanNames {
-		pwm := pwms[name]
-		if pwm == "" {
-			pwm = "N/A"
+	for _, name := range ipmi.FanNames {
+		pwm := "N/A"
+		if v, ok := pwms[name]; ok {
+			pwm = fmt.Sprintf("%d%%", v)
 		}
 		rpm := rpmMap[name]
 		if rpm == "" {
@@ -216,11 +147,11 @@ func getStatus(asJson bool) {
 
 	// Output results
 	if asJson {
-		report := StatusReport{Fans: fans, Thermals: thermals}
+		report := StatusReport{Fans: fans, Thermals: thermals, PSUs: psus, Health: health}
 		encoder := json.NewEncoder(os.Stdout)
 		encoder.SetIndent("", "  ")
 		if err := encoder.Encode(report); err != nil {
-			fmt.Printf("JSON Encoding Error: %v\n", err)
+			log.Errorf("JSON encoding: %v", err)
 		}
 	} else {
 		// Table 1: Cooling System (Fans)
@@ -243,7 +174,32 @@ func getStatus(asJson bool) {
 			fmt.Printf("| %-20s | %-25s |\n", t.Name, t.Value)
 		}
 		fmt.Println("+----------------------+---------------------------+")
+
+		// Table 3: Power & Chassis Health
+		if len(psus) > 0 || health != (HealthStatus{}) {
+			fmt.Println("+----------------------+-----------+-----------+---------+")
+			fmt.Println("| POWER & CHASSIS HEALTH                                  |")
+			fmt.Println("+----------------------+-----------+-----------+---------+")
+			fmt.Printf("| %-20s | %-9s | %-9s | %-7s |\n", "PSU", "INPUT", "OUTPUT", "WATTS")
+			fmt.Println("+----------------------+-----------+-----------+---------+")
+			for _, p := range psus {
+				if !p.Present {
+					fmt.Printf("| %-20s | %-9s | %-9s | %-7s |\n", p.Name, "-", "-", "-")
+					continue
+				}
+				fmt.Printf("| %-20s | %-9s | %-9s | %-7.0f |\n", p.Name, okState(p.InputOK), okState(p.OutputOK), p.Watts)
+			}
+			fmt.Println("+----------------------+-----------+-----------+---------+")
+			fmt.Printf("Drive Fault: %v, Cooling/Fan Fault: %v\n", health.DriveFault, health.CoolingFault)
+		}
+	}
+}
+
+func okState(ok bool) string {
+	if ok {
+		return "OK"
 	}
+	return "FAULT"
 }
 
 // checkDependencies verifies if ipmitool is installed in PATH
@@ -252,79 +208,160 @@ func checkDependencies() error {
 	return err
 }
 
-func main() {
-	if err := checkDependencies(); err != nil {
-		fmt.Println("Error: 'ipmitool' not found in PATH. Install it via: apt install ipmitool")
-		os.Exit(1)
+// newClient builds the BMC transport selected by the global --host/--user/
+// --password/--interface flags, defaulting to the original ipmitool-backed
+// client when none of them are set. It returns the remaining, unrecognized
+// arguments so subcommand parsing is unaffected by where these flags
+// appear on the command line.
+func newClient(args []string) (ipmi.Client, []string, error) {
+	fs := flag.NewFlagSet("rd450x-fan-control", flag.ContinueOnError)
+	fs.SetOutput(new(strings.Builder)) // suppress automatic usage output; we print our own
+	host := fs.String("host", "", "BMC hostname/IP for the native lanplus interface")
+	user := fs.String("user", "", "BMC username for the native lanplus interface")
+	password := fs.String("password", "", "BMC password for the native lanplus interface")
+	iface := fs.String("interface", "", "transport: lanplus (native RMCP+), open (local /dev/ipmi0), or unset for ipmitool")
+
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		switch {
+		case strings.HasPrefix(args[i], "--host="), strings.HasPrefix(args[i], "--user="),
+			strings.HasPrefix(args[i], "--password="), strings.HasPrefix(args[i], "--interface="):
+			fs.Parse([]string{args[i]})
+		case args[i] == "--host" || args[i] == "--user" || args[i] == "--password" || args[i] == "--interface":
+			if i+1 >= len(args) {
+				return nil, nil, fmt.Errorf("missing value for %s", args[i])
+			}
+			fs.Parse(args[i : i+2])
+			i++
+		default:
+			rest = append(rest, args[i])
+		}
+	}
+
+	switch *iface {
+	case "open":
+		c, err := ipmi.NewOpenClient(*host)
+		return c, rest, err
+	case "lanplus":
+		c, err := ipmi.NewNativeClient(*host, 0, *user, *password)
+		return c, rest, err
+	case "":
+		if *host != "" {
+			c, err := ipmi.NewNativeClient(*host, 0, *user, *password)
+			return c, rest, err
+		}
+		return ipmi.IpmitoolClient{}, rest, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown --interface %q (want lanplus or open)", *iface)
 	}
+}
 
+func main() {
 	if len(os.Args) < 2 {
 		fmt.Println("Usage:")
 		fmt.Println("  rd450x-fan-control status [--json]")
 		fmt.Println("  rd450x-fan-control get <id>")
 		fmt.Println("  rd450x-fan-control set <id|all> <speed>")
+		fmt.Println("  rd450x-fan-control daemon [--config /etc/rd450x-fan-control/config.yaml]")
+		fmt.Println("  rd450x-fan-control exporter [--listen :9101] [--scrape-interval 5s] [--collector.sensors=false] [--log-file path] [--log-level info]")
+		fmt.Println("  Add --host <ip> --user <user> --password <pass> --interface=lanplus to talk to a remote BMC natively,")
+		fmt.Println("  or --interface=open to use the local /dev/ipmi0 device, instead of shelling out to ipmitool.")
+		return
+	}
+
+	log := logger.New(logger.Config{})
+
+	client, args, err := newClient(os.Args[1:])
+	if err != nil {
+		log.Errorf("%v", err)
+		os.Exit(1)
+	}
+	if _, isIpmitool := client.(ipmi.IpmitoolClient); isIpmitool {
+		if err := checkDependencies(); err != nil {
+			log.Errorf("'ipmitool' not found in PATH. Install it via: apt install ipmitool")
+			os.Exit(1)
+		}
+	}
+	if len(args) == 0 {
+		log.Errorf("missing command")
 		return
 	}
+	os.Args = append([]string{os.Args[0]}, args...)
 
 	switch os.Args[1] {
 	case "status":
 		isJson := len(os.Args) > 2 && os.Args[2] == "--json"
-		getStatus(isJson)
+		getStatus(client, isJson, log)
 	case "get":
 		if len(os.Args) < 3 {
-			fmt.Println("Error: Missing arguments. Example: rd450x-fan-control get 01")
+			log.Errorf("missing arguments. Example: rd450x-fan-control get 01")
 			return
 		}
-		getSpeed(os.Args[2])
+		getSpeed(client, os.Args[2], log)
 	case "set":
 		if len(os.Args) < 4 {
-			fmt.Println("Error: Missing arguments. Example: rd450x-fan-control set all 50")
+			log.Errorf("missing arguments. Example: rd450x-fan-control set all 50")
 			return
 		}
-		setSpeed(os.Args[2], os.Args[3])
+		setSpeed(client, os.Args[2], os.Args[3], log)
+	case "daemon":
+		configPath := "/etc/rd450x-fan-control/config.yaml"
+		if len(os.Args) > 3 && os.Args[2] == "--config" {
+			configPath = os.Args[3]
+		}
+		if err := RunDaemon(client, configPath); err != nil {
+			log.Errorf("%v", err)
+			os.Exit(1)
+		}
+	case "exporter":
+		fs := flag.NewFlagSet("exporter", flag.ExitOnError)
+		listen := fs.String("listen", ":9101", "address to serve /metrics on")
+		scrapeInterval := fs.Duration("scrape-interval", 5*time.Second, "how long to cache BMC readings between scrapes")
+		collectSensors := fs.Bool("collector.sensors", true, "collect fan RPM, temperature, and airflow sensors")
+		logFile := fs.String("log-file", "", "write logs to this file (with rotation) instead of stderr")
+		logLevel := fs.String("log-level", "info", "log level: debug, info, warn, or error")
+		fs.Parse(os.Args[2:])
+
+		opts := ExporterOptions{
+			Listen:         *listen,
+			ScrapeInterval: *scrapeInterval,
+			CollectSensors: *collectSensors,
+			Logging:        logger.Config{Level: *logLevel, File: *logFile},
+		}
+		if err := RunExporter(client, opts); err != nil {
+			log.Errorf("%v", err)
+			os.Exit(1)
+		}
 	case "testrun":
 		fmt.Println("--- STARTING AUTOMATED TEST SEQUENCE ---")
 
 		fmt.Println("[STEP 0] Saving current fan speeds...")
-		originalPWMs := getPWMs()
-
-		// Map of fan names to their corresponding IDs for the 'set' command
-		fanIDs := map[string]string{
-			"System Fan1": "01",
-			"System Fan2": "02",
-			"System Fan3": "03",
-			"System Fan4": "04",
-			"CPU Fan1":    "05",
-			"CPU Fan2":    "06",
-		}
-
-		// Save only the fans that returned a valid reading (not N/A)
-		savedSpeeds := make(map[string]string)
-		for name, id := range fanIDs {
-			pwmStr := originalPWMs[name]
-			if pwmStr != "N/A" && pwmStr != "" {
-				// Strip the "%" sign to get the integer string (e.g., "50%" -> "50")
-				speedVal := strings.TrimSuffix(pwmStr, "%")
-				savedSpeeds[id] = speedVal
-				fmt.Printf("    ID %s (%s) backed up at %s%%\n", id, name, speedVal)
+		originalPWMs, _ := client.GetPWMs()
+
+		// Save only the fans that returned a valid reading
+		savedSpeeds := make(map[string]int)
+		for name, id := range ipmi.FanIDByName {
+			if speed, ok := originalPWMs[name]; ok {
+				savedSpeeds[id] = speed
+				fmt.Printf("    ID %s (%s) backed up at %d%%\n", id, name, speed)
 			}
 		}
 
 		fmt.Println("\n[STEP 1] Testing 'set all' command (setting to 40%)...")
-		setSpeed("all", "40")
+		setSpeed(client, "all", "40", log)
 
 		fmt.Println("\n[STEP 2] Testing 'get' command for Fan 01...")
-		getSpeed("01")
+		getSpeed(client, "01", log)
 
 		fmt.Println("\n[STEP 3] Displaying full status dashboard...")
-		getStatus(false)
+		getStatus(client, false, log)
 
 		fmt.Println("\n[STEP 4] Restoring original fan speeds...")
 		for id, speed := range savedSpeeds {
-			setSpeed(id, speed)
+			setSpeed(client, id, strconv.Itoa(speed), log)
 		}
 		fmt.Println("\n--- TEST SEQUENCE COMPLETE ---")
 	default:
-		fmt.Printf("Error: Unknown command '%s'\n", os.Args[1])
+		log.Errorf("unknown command '%s'", os.Args[1])
 	}
 }
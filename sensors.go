@@ -0,0 +1,57 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+// SensorReading is the parsed numeric form of one line of `ipmitool sensor
+// list` output, shared by the CLI status table, the daemon's curve
+// evaluation, and the Prometheus exporter.
+type SensorReading struct {
+	Name  string
+	Value float64
+	Unit  string
+	IsFan bool
+}
+
+// parseSensorList parses the raw output of `ipmitool sensor list` into
+// individual readings, skipping disconnected sensors the same way getStatus
+// always has.
+func parseSensorList(raw string) []SensorReading {
+	var readings []SensorReading
+
+	for _, line := range strings.Split(raw, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) < 3 {
+			continue
+		}
+
+		name := strings.TrimSpace(parts[0])
+		valStr := strings.TrimSpace(parts[1])
+		unit := strings.TrimSpace(parts[2])
+		lineUpper := strings.ToUpper(line)
+		isFan := strings.Contains(lineUpper, "FAN") && !strings.Contains(lineUpper, "POWER")
+
+		if valStr == "na" {
+			continue
+		}
+
+		val, err := strconv.ParseFloat(valStr, 64)
+		if err != nil {
+			continue
+		}
+
+		if !isFan && val == 0 && unit == "degrees C" {
+			continue
+		}
+
+		readings = append(readings, SensorReading{Name: name, Value: val, Unit: unit, IsFan: isFan})
+	}
+
+	return readings
+}
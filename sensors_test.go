@@ -0,0 +1,38 @@
+package main
+
+import "testing"
+
+func TestParseSensorList(t *testing.T) {
+	raw := `System Fan1      | 1200.000   | RPM        | ok    | na        | na        | na        | na        | na        | na
+CPU Fan1         | na         | RPM        | na    | na        | na        | na        | na        | na        | na
+CPU Temp         | 45.000     | degrees C  | ok    | na        | na        | na        | na        | na        | na
+Inlet Temp       | 0.000      | degrees C  | ok    | na        | na        | na        | na        | na        | na
+`
+
+	readings := parseSensorList(raw)
+
+	var fan, cpuTemp *SensorReading
+	for i := range readings {
+		switch readings[i].Name {
+		case "System Fan1":
+			fan = &readings[i]
+		case "CPU Temp":
+			cpuTemp = &readings[i]
+		case "Inlet Temp":
+			t.Errorf("expected disconnected 0 degrees C sensor to be skipped, got %+v", readings[i])
+		}
+	}
+
+	if fan == nil || fan.Value != 1200 || !fan.IsFan {
+		t.Fatalf("System Fan1 reading not parsed correctly: %+v", fan)
+	}
+	if cpuTemp == nil || cpuTemp.Value != 45 || cpuTemp.IsFan {
+		t.Fatalf("CPU Temp reading not parsed correctly: %+v", cpuTemp)
+	}
+
+	for _, r := range readings {
+		if r.Name == "CPU Fan1" {
+			t.Errorf("expected na CPU Fan1 reading to be skipped, got %+v", r)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+// Package systemd implements the sd_notify(3) wire protocol directly,
+// without a dependency on libsystemd, so Type=notify units and
+// WatchdogSec= work for the daemon and exporter subcommands.
+package systemd
+
+import (
+	"net"
+	"os"
+)
+
+// Notify sends a state string (e.g. "READY=1") to the socket named by
+// $NOTIFY_SOCKET. It is a no-op, returning nil, when that variable isn't
+// set - i.e. whenever the process isn't running under a systemd
+// Type=notify unit.
+func Notify(state string) error {
+	addr := os.Getenv("NOTIFY_SOCKET")
+	if addr == "" {
+		return nil
+	}
+
+	conn, err := net.Dial("unixgram", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.Write([]byte(state))
+	return err
+}
+
+// Ready notifies systemd that startup has finished.
+func Ready() error { return Notify("READY=1") }
+
+// Watchdog sends a watchdog keepalive, to be called at least as often as
+// the unit's WatchdogSec=.
+func Watchdog() error { return Notify("WATCHDOG=1") }
+
+// Stopping notifies systemd that a graceful shutdown has begun.
+func Stopping() error { return Notify("STOPPING=1") }
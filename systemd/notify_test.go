@@ -0,0 +1,41 @@
+package systemd
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNotifyIsNoopWithoutSocket(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+	if err := Notify("READY=1"); err != nil {
+		t.Errorf("Notify with no $NOTIFY_SOCKET should be a no-op, got: %v", err)
+	}
+}
+
+func TestNotifySendsState(t *testing.T) {
+	sockPath := filepath.Join(t.TempDir(), "notify.sock")
+	addr := &net.UnixAddr{Name: sockPath, Net: "unixgram"}
+	listener, err := net.ListenUnixgram("unixgram", addr)
+	if err != nil {
+		t.Fatalf("ListenUnixgram: %v", err)
+	}
+	defer listener.Close()
+
+	t.Setenv("NOTIFY_SOCKET", sockPath)
+
+	if err := Ready(); err != nil {
+		t.Fatalf("Ready: %v", err)
+	}
+
+	buf := make([]byte, 64)
+	listener.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, err := listener.Read(buf)
+	if err != nil {
+		t.Fatalf("reading from notify socket: %v", err)
+	}
+	if got := string(buf[:n]); got != "READY=1" {
+		t.Errorf("received %q, want %q", got, "READY=1")
+	}
+}